@@ -0,0 +1,45 @@
+package plotext
+
+import "image/color"
+
+// ColorPalette is the Okabe–Ito palette of colors that stay distinguishable
+// with the common forms of color blindness, ordered so that the first few are
+// the most distinct on a white background.
+var ColorPalette = []color.Color{
+	color.RGBA{R: 0x00, G: 0x72, B: 0xb2, A: 0xff}, // blue
+	color.RGBA{R: 0xe6, G: 0x9f, B: 0x00, A: 0xff}, // orange
+	color.RGBA{R: 0x00, G: 0x9e, B: 0x73, A: 0xff}, // bluish green
+	color.RGBA{R: 0xd5, G: 0x5e, B: 0x00, A: 0xff}, // vermillion
+	color.RGBA{R: 0xcc, G: 0x79, B: 0xa7, A: 0xff}, // reddish purple
+	color.RGBA{R: 0x56, G: 0xb4, B: 0xe9, A: 0xff}, // sky blue
+	color.RGBA{R: 0xf0, G: 0xe4, B: 0x42, A: 0xff}, // yellow
+	color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0xff}, // black
+}
+
+// ColorCycler hands out the colors of a palette in order, starting over after
+// the last one. Assigning each QuantizedLine the next color as its line color
+// gives it a matching envelope fill as well. The zero value cycles through
+// ColorPalette.
+type ColorCycler struct {
+	// Colors is the palette to cycle through. ColorPalette is used if it is
+	// empty.
+	Colors []color.Color
+
+	next int
+}
+
+// NextColor returns the next color of the palette.
+func (cc *ColorCycler) NextColor() color.Color {
+	colors := cc.Colors
+	if len(colors) == 0 {
+		colors = ColorPalette
+	}
+	c := colors[cc.next%len(colors)]
+	cc.next++
+	return c
+}
+
+// Reset makes the cycler start over from the first color.
+func (cc *ColorCycler) Reset() {
+	cc.next = 0
+}
@@ -0,0 +1,34 @@
+package plotext
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestColorCycler(t *testing.T) {
+	var cc ColorCycler
+
+	seen := make(map[color.Color]bool)
+	for i := range ColorPalette {
+		c := cc.NextColor()
+		if seen[c] {
+			t.Errorf("color %d repeats %v", i, c)
+		}
+		seen[c] = true
+	}
+
+	// wraps around
+	if c := cc.NextColor(); c != ColorPalette[0] {
+		t.Errorf("got %v after the last color, expected %v", c, ColorPalette[0])
+	}
+
+	cc.Reset()
+	if c := cc.NextColor(); c != ColorPalette[0] {
+		t.Errorf("got %v after Reset, expected %v", c, ColorPalette[0])
+	}
+
+	custom := ColorCycler{Colors: []color.Color{color.White, color.Black}}
+	if a, b, c := custom.NextColor(), custom.NextColor(), custom.NextColor(); a != color.White || b != color.Black || c != color.White {
+		t.Errorf("custom palette: got %v, %v, %v", a, b, c)
+	}
+}
@@ -0,0 +1,71 @@
+package plotext
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadSampleBufferCSV loads the zero-based `column` of a CSV file as samples
+// and constructs a SampleBuffer with the given sample rate `fs`. If header is
+// true, the first row is skipped.
+func LoadSampleBufferCSV(path string, column int, fs float64, header bool) (*SampleBuffer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s, err := ReadSampleBufferCSV(f, column, fs, header)
+	if err != nil {
+		return nil, fmt.Errorf("plotext: %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// ReadSampleBufferCSV is like LoadSampleBufferCSV, but reads the CSV data from
+// r.
+func ReadSampleBufferCSV(r io.Reader, column int, fs float64, header bool) (*SampleBuffer, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	if header {
+		if _, err := cr.Read(); err != nil {
+			if err == io.EOF {
+				return nil, errors.New("missing header")
+			}
+			return nil, err
+		}
+	}
+
+	var p []float64
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		line, _ := cr.FieldPos(0)
+		if column >= len(record) {
+			return nil, fmt.Errorf("line %d: no column %d", line, column)
+		}
+
+		v, err := strconv.ParseFloat(strings.TrimSpace(record[column]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		p = append(p, v)
+	}
+
+	return &SampleBuffer{
+		Samples:    p,
+		SampleRate: fs,
+	}, nil
+}
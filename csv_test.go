@@ -0,0 +1,54 @@
+package plotext
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testCSV = `time,voltage,current
+0,1.5,0.1
+0.001,1.25,0.2
+0.002, -0.5 ,0.3
+`
+
+func TestReadSampleBufferCSV(t *testing.T) {
+	s, err := ReadSampleBufferCSV(strings.NewReader(testCSV), 1, 1000, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ex := []float64{1.5, 1.25, -0.5}; !slices.Equal(s.Samples, ex) {
+		t.Errorf("got %v, expected %v", s.Samples, ex)
+	}
+	if s.SampleRate != 1000 {
+		t.Errorf("got sample rate %f, expected 1000", s.SampleRate)
+	}
+
+	// the header doesn't parse as a number
+	_, err = ReadSampleBufferCSV(strings.NewReader(testCSV), 1, 1000, false)
+	if err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("got error %v, expected a parse error on line 1", err)
+	}
+
+	_, err = ReadSampleBufferCSV(strings.NewReader("1,2\n3\n"), 1, 1000, false)
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("got error %v, expected a missing column error on line 2", err)
+	}
+}
+
+func TestLoadSampleBufferCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scope.csv")
+	if err := os.WriteFile(path, []byte(testCSV), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadSampleBufferCSV(path, 2, 1000, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ex := []float64{0.1, 0.2, 0.3}; !slices.Equal(s.Samples, ex) {
+		t.Errorf("got %v, expected %v", s.Samples, ex)
+	}
+}
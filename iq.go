@@ -0,0 +1,75 @@
+package plotext
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/cmplx"
+	"os"
+)
+
+// IQSampleBuffer is a complex-valued counterpart of SampleBuffer for I/Q data,
+// such as captures from a software defined radio. It isn't a plotter.XYer
+// itself; plot one of the real-valued buffers derived from it instead.
+type IQSampleBuffer struct {
+	Samples    []complex128 // I + jQ
+	SampleRate float64      // samples per second
+	StartIndex int          // sample index of Samples[0] (e.g. within a larger file)
+	TimeOffset float64      // time of sample index 0 in seconds
+}
+
+// Len returns the number of samples.
+func (s *IQSampleBuffer) Len() int {
+	return len(s.Samples)
+}
+
+// Magnitude returns a SampleBuffer holding |I + jQ| of each sample, with the
+// same sample rate and time coordinates as s.
+func (s *IQSampleBuffer) Magnitude() *SampleBuffer {
+	return s.real(cmplx.Abs)
+}
+
+// Phase returns a SampleBuffer holding the phase of each sample in radians in
+// [-π, π], with the same sample rate and time coordinates as s.
+func (s *IQSampleBuffer) Phase() *SampleBuffer {
+	return s.real(cmplx.Phase)
+}
+
+// real returns a SampleBuffer holding f of each sample of s.
+func (s *IQSampleBuffer) real(f func(complex128) float64) *SampleBuffer {
+	p := make([]float64, len(s.Samples))
+	for i, v := range s.Samples {
+		p[i] = f(v)
+	}
+	return &SampleBuffer{
+		Samples:    p,
+		SampleRate: s.SampleRate,
+		StartIndex: s.StartIndex,
+		TimeOffset: s.TimeOffset,
+	}
+}
+
+// LoadIQSampleBuffer loads `size` I/Q samples from the file at `path`, stored
+// as interleaved pairs of big-endian IEEE 754 float64 values, I first. The
+// sample rate fs is in samples (pairs) per second.
+func LoadIQSampleBuffer(path string, size int, fs float64) (*IQSampleBuffer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// each I/Q sample is two float64 samples
+	p, err := readSamples(f, 2*size, binary.BigEndian, 64)
+	if err != nil {
+		return nil, fmt.Errorf("plotext: %s: %w", path, err)
+	}
+
+	s := &IQSampleBuffer{
+		Samples:    make([]complex128, size),
+		SampleRate: fs,
+	}
+	for i := range s.Samples {
+		s.Samples[i] = complex(p[2*i], p[2*i+1])
+	}
+	return s, nil
+}
@@ -0,0 +1,63 @@
+package plotext
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIQSampleBuffer(t *testing.T) {
+	s := &IQSampleBuffer{
+		Samples:    []complex128{1, 1i, -2, complex(3, -4)},
+		SampleRate: 100,
+		StartIndex: 10,
+	}
+
+	exMag := []float64{1, 1, 2, 5}
+	exPhase := []float64{0, math.Pi / 2, math.Pi, math.Atan2(-4, 3)}
+
+	mag, phase := s.Magnitude(), s.Phase()
+	for i := range s.Samples {
+		if math.Abs(mag.Samples[i]-exMag[i]) > 1e-12 {
+			t.Errorf("magnitude %d: got %v, expected %v", i, mag.Samples[i], exMag[i])
+		}
+		if math.Abs(phase.Samples[i]-exPhase[i]) > 1e-12 {
+			t.Errorf("phase %d: got %v, expected %v", i, phase.Samples[i], exPhase[i])
+		}
+	}
+
+	if x, _ := mag.XY(0); x != 0.1 || mag.SampleRate != 100 {
+		t.Errorf("got first x %v at %v, expected 0.1 at 100", x, mag.SampleRate)
+	}
+}
+
+func TestLoadIQSampleBuffer(t *testing.T) {
+	// I/Q pairs written as a flat run of samples
+	flat := &SampleBuffer{Samples: []float64{1, 2, 3, 4, 5, 6}}
+	path := filepath.Join(t.TempDir(), "iq.bin")
+	if err := SaveSampleBuffer(path, flat); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadIQSampleBuffer(path, 3, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ex := []complex128{complex(1, 2), complex(3, 4), complex(5, 6)}
+	if s.Len() != len(ex) || s.SampleRate != 1000 {
+		t.Fatalf("got %d samples at %v, expected %d at 1000", s.Len(), s.SampleRate, len(ex))
+	}
+	for i := range ex {
+		if s.Samples[i] != ex[i] {
+			t.Errorf("sample %d: got %v, expected %v", i, s.Samples[i], ex[i])
+		}
+	}
+
+	if _, err := LoadIQSampleBuffer(path, 4, 1000); err == nil {
+		t.Error("expected an error reading past the end of the file")
+	}
+	if _, err := LoadIQSampleBuffer(filepath.Join(t.TempDir(), "missing"), 1, 1000); !os.IsNotExist(err) {
+		t.Errorf("got %v, expected a not-exist error", err)
+	}
+}
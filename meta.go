@@ -0,0 +1,64 @@
+package plotext
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// sampleMeta is the JSON sidecar read by LoadSampleBufferWithMeta.
+type sampleMeta struct {
+	SampleRate float64  `json:"sample_rate"`
+	Length     *int     `json:"length"`
+	Channels   int      `json:"channels"`
+	Scale      *float64 `json:"scale"`
+}
+
+// LoadSampleBufferWithMeta loads a big-endian binary file of float64 values
+// like LoadSampleBuffer, taking its parameters from the JSON sidecar file at
+// binPath+".json" instead of arguments. The sidecar must give a positive
+// "sample_rate" in Hz, and may give the number of samples as "length" (all
+// samples in the file are read if it is missing), a "scale" to multiply each
+// sample by, and a "channels" count, which must be 1 if given; use
+// LoadSampleBuffers for interleaved files.
+func LoadSampleBufferWithMeta(binPath string) (*SampleBuffer, error) {
+	metaPath := binPath + ".json"
+	b, err := os.ReadFile(metaPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("plotext: %s: missing metadata sidecar %s", binPath, metaPath)
+	} else if err != nil {
+		return nil, err
+	}
+
+	var meta sampleMeta
+	if err := json.Unmarshal(b, &meta); err != nil {
+		return nil, fmt.Errorf("plotext: %s: malformed metadata: %w", metaPath, err)
+	}
+	if !(meta.SampleRate > 0) {
+		return nil, fmt.Errorf("plotext: %s: sample_rate must be positive, got %g", metaPath, meta.SampleRate)
+	}
+	if meta.Length != nil && *meta.Length < 0 {
+		return nil, fmt.Errorf("plotext: %s: invalid length %d", metaPath, *meta.Length)
+	}
+	if meta.Channels != 0 && meta.Channels != 1 {
+		return nil, fmt.Errorf("plotext: %s: %d channels given, only single-channel files are supported", metaPath, meta.Channels)
+	}
+
+	var s *SampleBuffer
+	if meta.Length != nil {
+		s, err = LoadSampleBuffer(binPath, *meta.Length, meta.SampleRate)
+	} else {
+		s, err = LoadSampleBufferAll(binPath, meta.SampleRate)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if meta.Scale != nil {
+		for i := range s.Samples {
+			s.Samples[i] *= *meta.Scale
+		}
+	}
+	return s, nil
+}
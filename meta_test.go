@@ -0,0 +1,59 @@
+package plotext
+
+import (
+	"encoding/binary"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadSampleBufferWithMeta(t *testing.T) {
+	path := writeSamples(t, binary.BigEndian, []float64{1, 2, 3, 4, 5})
+	writeMeta := func(meta string) {
+		t.Helper()
+		if err := os.WriteFile(path+".json", []byte(meta), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := LoadSampleBufferWithMeta(path); err == nil || !strings.Contains(err.Error(), "missing metadata") {
+		t.Errorf("got error %v for a missing sidecar", err)
+	}
+
+	writeMeta(`{"sample_rate": 2000, "length": 4, "channels": 1, "scale": 0.5, "comment": "ignored"}`)
+	s, err := LoadSampleBufferWithMeta(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.SampleRate != 2000 {
+		t.Errorf("got sample rate %v, expected 2000", s.SampleRate)
+	}
+	if ex := []float64{0.5, 1, 1.5, 2}; !slices.Equal(s.Samples, ex) {
+		t.Errorf("got samples %v, expected %v", s.Samples, ex)
+	}
+
+	// without a length, the whole file is read
+	writeMeta(`{"sample_rate": 10}`)
+	s, err = LoadSampleBufferWithMeta(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Len() != 5 {
+		t.Errorf("got %d samples, expected 5", s.Len())
+	}
+
+	for _, meta := range []string{
+		`{"sample_rate": 10`,
+		`{"sample_rate": "fast"}`,
+		`{"length": 4}`,
+		`{"sample_rate": 10, "length": -1}`,
+		`{"sample_rate": 10, "channels": 2}`,
+		`{"sample_rate": 10, "length": 6}`,
+	} {
+		writeMeta(meta)
+		if _, err := LoadSampleBufferWithMeta(path); err == nil {
+			t.Errorf("%s: expected an error", meta)
+		}
+	}
+}
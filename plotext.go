@@ -2,12 +2,20 @@
 package plotext
 
 import (
+	"cmp"
+	"compress/gzip"
 	"encoding/binary"
+	"fmt"
 	"image/color"
+	"io"
 	"log"
 	"math"
 	"os"
+	"runtime"
 	"slices"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/dustin/go-humanize"
 	"gonum.org/v1/plot"
@@ -18,92 +26,740 @@ import (
 )
 
 // QuantizedLine is a plotter.Line derivative that aggregates line points
-// quantized into buckets of 1 vg.Point wide when drawing onto a canvas. It
-// assumes that the points are more or less evenly distributed along x, which is
-// sufficient for most data acquisition sources.
-//
-// TODO: make it not assume that. Aggregation needs to happen in the x-domain
-// (float) rather than the i-domain (sample index).
+// quantized into buckets of 1 vg.Point wide when drawing onto a canvas. Points
+// are assigned to buckets by their X coordinate, so the data need not be evenly
+// distributed along x.
 type QuantizedLine struct {
 	*plotter.Line
+
+	// FillOpacity scales the alpha of the line color to get the color of the
+	// area between the bounding lines. It is clamped to [0, 1], and 0.5 is used
+	// if it is zero.
+	FillOpacity float64
+
+	// FillColor, if not nil, is used as-is for the area between the bounding
+	// lines instead of the line color scaled by FillOpacity. Note that it
+	// shadows the FillColor of the embedded Line, which still controls the
+	// fill below the line when it is drawn without aggregation.
+	FillColor color.Color
+
+	// DrawMean enables drawing a line through the mean of each bucket on top of
+	// the bounding lines when the data is aggregated.
+	DrawMean bool
+
+	// PointsPerUnit is the number of points per bucket above which the data
+	// is aggregated. With the default of one bucket per vg.Point of canvas
+	// width, that is points per vg.Point; Oversample and Buckets change the
+	// number of buckets and so scale the threshold too. Lower values favor
+	// drawing the raw line, and higher values favor aggregation. 2 is used if
+	// it is zero.
+	PointsPerUnit float64
+
+	// EnvelopeMode selects how the bounds of each bucket are computed when
+	// the data is aggregated.
+	EnvelopeMode EnvelopeMode
+
+	// SigmaMultiplier is the number of standard deviations either side of the
+	// mean covered by the envelope in StdDev mode. 1 is used if it is zero.
+	SigmaMultiplier float64
+
+	// Buckets, if positive, is a fixed number of buckets to aggregate the data
+	// into instead of one per vg.Point of canvas width, for output that looks
+	// the same at any size. The data is then aggregated when there are more
+	// than PointsPerUnit points per bucket.
+	Buckets int
+
+	// SolidEnvelope draws the bounding lines of aggregated data without the
+	// dashes of the line style, which are lost in the noise of a dense
+	// envelope anyway. The mean line keeps them.
+	SolidEnvelope bool
+
+	// Oversample, if greater than 1, multiplies the number of buckets per
+	// vg.Point of canvas width, for smoother envelope edges in vector output
+	// that is rasterized at a high resolution. Since PointsPerUnit counts
+	// points per bucket, it also raises the number of points per vg.Point
+	// needed for aggregation by the same factor. It has no effect if Buckets
+	// is set.
+	Oversample int
+
+	// GradientFill fades the area between the bounding lines from the fill
+	// color at the mean to EdgeOpacity times its alpha at the bounds. No
+	// gonum backend draws real gradients, so the fade is approximated by
+	// eight bands of flat color either side of the mean, which works the same
+	// on any canvas. BuildPolygon doesn't reflect it, and still returns the
+	// flat envelope.
+	GradientFill bool
+
+	// EdgeOpacity scales the alpha of the fill color at the bounds when
+	// GradientFill is set. It is clamped to [0, 1], so the default of 0
+	// fades to fully transparent.
+	EdgeOpacity float64
+
+	cache     envelopeCache
+	polygon   envelopeCache // for BuildPolygon, which aggregates over the data range
+	monotonic monotonicCache
+}
+
+// envelopeCache holds the last aggregated envelope of a QuantizedLine along
+// with what it was computed from, so redraws at the same size and range can
+// skip aggregation. Plot and BuildPolygon each have their own, so that
+// alternating between them doesn't recompute both every time.
+type envelopeCache struct {
+	data  *plotter.XY // first element of Line.XYs, to notice replacement
+	len   int
+	n     int
+	xmin  float64
+	xmax  float64
+	e     envelope
+	valid bool
 }
 
-func aggregate(xyer plotter.XYer, n int) (mins, maxes plotter.XYs) {
-	mins = make(plotter.XYs, 0, n)
-	maxes = make(plotter.XYs, 0, n)
+// monotonicCache holds whether the X values of Line.XYs were last found to be
+// nondecreasing, along with what that was computed from.
+type monotonicCache struct {
+	data      *plotter.XY
+	len       int
+	monotonic bool
+	valid     bool
+}
 
-	l := xyer.Len()
-	ys := make([]float64, int(math.Ceil(float64(l)/float64(n))))
-	di := 0
-	x := 0.0
+// EnvelopeMode selects the bounds of the envelope drawn by QuantizedLine.
+type EnvelopeMode int
 
-	for i := 0; i < l; {
-		x, _ = xyer.XY(i)
-		for di = 0; di < len(ys); di++ {
-			if i >= l {
-				break
-			}
-			_, ys[di] = xyer.XY(i)
-			i++
+const (
+	// MinMax bounds each bucket by its minimum and maximum values.
+	MinMax EnvelopeMode = iota
+
+	// StdDev bounds each bucket by its mean plus or minus a multiple of its
+	// standard deviation, which is less sensitive to outliers.
+	StdDev
+)
+
+// NewQuantizedLine returns a QuantizedLine for the given points that uses the
+// default line style, mirroring plotter.NewLine. Unlike plotter.NewLine, it
+// accepts points with a NaN coordinate, which mark gaps in the data that break
+// the line and the envelope. Infinite coordinates are still an error.
+func NewQuantizedLine(xyer plotter.XYer) (*QuantizedLine, error) {
+	line, err := newGapLine(xyer)
+	if err != nil {
+		return nil, err
+	}
+	return &QuantizedLine{Line: line}, nil
+}
+
+// newGapLine is like plotter.NewLine, but lets NaN coordinates through as gaps.
+func newGapLine(xyer plotter.XYer) (*plotter.Line, error) {
+	xys := make(plotter.XYs, xyer.Len())
+	for i := range xys {
+		xys[i].X, xys[i].Y = xyer.XY(i)
+		if math.IsInf(xys[i].X, 0) || math.IsInf(xys[i].Y, 0) {
+			return nil, plotter.ErrInfinity
+		}
+	}
+	return &plotter.Line{
+		XYs:       xys,
+		LineStyle: plotter.DefaultLineStyle,
+	}, nil
+}
+
+// Aggregate divides the X range of the data into `buckets` intervals of equal
+// width and returns the minimum and maximum Y of the points falling into each
+// one, forming the bounding envelope of the data. The X of each returned point
+// is the smallest X in its bucket. Points with a NaN or infinite coordinate are
+// ignored. Empty buckets are skipped, so the results may have fewer than
+// `buckets` points, and are sorted by X.
+func Aggregate(xyer plotter.XYer, buckets int) (mins, maxes plotter.XYs) {
+	xmin, xmax, _, _ := finiteRange(xyer)
+	e := aggregate(xyer, buckets, xmin, xmax)
+	return e.mins, e.maxes
+}
+
+// finiteRange is like plotter.XYRange, but ignores points with a NaN or
+// infinite coordinate, which would otherwise poison the range. It returns an
+// inverted infinite range if there are no such points.
+func finiteRange(xyer plotter.XYer) (xmin, xmax, ymin, ymax float64) {
+	xmin, xmax = math.Inf(1), math.Inf(-1)
+	ymin, ymax = math.Inf(1), math.Inf(-1)
+	for i := 0; i < xyer.Len(); i++ {
+		x, y := xyer.XY(i)
+		if !isFinite(x) || !isFinite(y) {
+			continue
 		}
-		mins = append(mins, plotter.XY{X: x, Y: slices.Min(ys)})
-		maxes = append(maxes, plotter.XY{X: x, Y: slices.Max(ys)})
+		xmin, xmax = min(xmin, x), max(xmax, x)
+		ymin, ymax = min(ymin, y), max(ymax, y)
 	}
+	return
+}
+
+// AggregateAll is like Aggregate, but aggregates many XYers concurrently using
+// up to GOMAXPROCS goroutines. The results are in the same order as xyers and
+// are identical to calling Aggregate on each one.
+func AggregateAll(xyers []plotter.XYer, buckets int) (mins, maxes []plotter.XYs) {
+	mins = make([]plotter.XYs, len(xyers))
+	maxes = make([]plotter.XYs, len(xyers))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < min(runtime.GOMAXPROCS(0), len(xyers)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				mins[i], maxes[i] = Aggregate(xyers[i], buckets)
+			}
+		}()
+	}
+
+	for i := range xyers {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
 
 	return mins, maxes
 }
 
+// envelope holds per-bucket statistics of aggregated points, sorted by X.
+type envelope struct {
+	mins, maxes, means, stdDevs plotter.XYs
+
+	// segments are the [start, end) index ranges of runs of adjacent
+	// buckets, split wherever empty buckets were skipped
+	segments [][2]int
+}
+
+// bounds returns the lower and upper edges of the envelope for the given mode.
+// In StdDev mode, the edges are k standard deviations either side of the
+// mean.
+func (e *envelope) bounds(mode EnvelopeMode, k float64) (lower, upper plotter.XYs) {
+	if mode != StdDev {
+		return e.mins, e.maxes
+	}
+
+	lower = make(plotter.XYs, len(e.means))
+	upper = make(plotter.XYs, len(e.means))
+	for i, m := range e.means {
+		d := k * e.stdDevs[i].Y
+		lower[i] = plotter.XY{X: m.X, Y: m.Y - d}
+		upper[i] = plotter.XY{X: m.X, Y: m.Y + d}
+	}
+	return lower, upper
+}
+
+// aggregate divides the x-interval [xmin, xmax] into n buckets of equal width
+// and returns the minimum, maximum, mean, and standard deviation of Y of the
+// points falling into each bucket. Points outside of the interval, such as
+// those off screen on a zoomed axis, are ignored, as are points with a NaN or
+// infinite coordinate. The X of each returned point is the smallest X seen in
+// its bucket. Buckets that receive no valid points are skipped, splitting the
+// envelope into segments.
+func aggregate(xyer plotter.XYer, n int, xmin, xmax float64) envelope {
+	type bucket struct {
+		x, min, max float64
+		mean, m2    float64 // running mean and sum of squared deviations
+		count       int
+	}
+
+	if n <= 0 || xyer.Len() == 0 {
+		return envelope{}
+	}
+
+	buckets := make([]bucket, n)
+	width := (xmax - xmin) / float64(n)
+
+	for i := 0; i < xyer.Len(); i++ {
+		x, y := xyer.XY(i)
+		if !isFinite(x) || !isFinite(y) || x < xmin || x > xmax {
+			continue
+		}
+
+		j := 0
+		if width > 0 {
+			// xmax itself belongs to the last bucket
+			j = min(int(math.Floor((x-xmin)/width)), n-1)
+		}
+
+		b := &buckets[j]
+		if b.count == 0 {
+			*b = bucket{x: x, min: y, max: y, mean: y, count: 1}
+			continue
+		}
+		b.x = min(b.x, x)
+		b.min = min(b.min, y)
+		b.max = max(b.max, y)
+
+		// Welford's algorithm
+		b.count++
+		d := y - b.mean
+		b.mean += d / float64(b.count)
+		b.m2 += d * (y - b.mean)
+	}
+
+	e := envelope{
+		mins:    make(plotter.XYs, 0, n),
+		maxes:   make(plotter.XYs, 0, n),
+		means:   make(plotter.XYs, 0, n),
+		stdDevs: make(plotter.XYs, 0, n),
+	}
+
+	prev := -2
+	for j, b := range buckets {
+		if b.count == 0 {
+			continue
+		}
+		if j != prev+1 {
+			e.segments = append(e.segments, [2]int{len(e.mins), len(e.mins)})
+		}
+		prev = j
+		e.segments[len(e.segments)-1][1]++
+
+		e.mins = append(e.mins, plotter.XY{X: b.x, Y: b.min})
+		e.maxes = append(e.maxes, plotter.XY{X: b.x, Y: b.max})
+		e.means = append(e.means, plotter.XY{X: b.x, Y: b.mean})
+		e.stdDevs = append(e.stdDevs, plotter.XY{X: b.x, Y: math.Sqrt(b.m2 / float64(b.count))})
+	}
+
+	return e
+}
+
+// isFinite reports whether v is neither NaN nor infinite.
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
 // Plot draws the data to a `draw.Canvas.`
 //
-//   - If there are more than 2 data points per Canvas Point of width, the data
-//     is first aggregated into buckets per width Point before plotting the
-//     bounding min and max lines with an area fill in between using the line
-//     color with half the opacity.
-//   - Otherwise, the Line is plotted as-is.
+//   - If there are more than PointsPerUnit data points per Canvas Point of
+//     width (or per bucket, if Buckets is set), the data is first aggregated
+//     into buckets per width Point (or into Buckets buckets) before
+//     plotting the bounding lines (per EnvelopeMode) with an area fill in
+//     between using the line color with FillOpacity. If DrawMean is set, the
+//     per-bucket mean is drawn on top.
+//   - Otherwise, the Line is plotted as-is. This includes data whose X values
+//     ever decrease (e.g. an X-Y trace), since bucketing by X would merge
+//     unrelated parts of the trace.
 func (ql *QuantizedLine) Plot(c draw.Canvas, plt *plot.Plot) {
-	dx := int(c.Max.X - c.Min.X)
+	width := c.Max.X - c.Min.X
 
-	if ql.Line.XYs.Len() <= dx*2 {
-		ql.Line.Plot(c, plt)
+	if !ql.ShouldAggregate(width) {
+		ql.plotLine(c, plt)
 		return
 	}
 
-	mins, maxes := aggregate(ql.Line.XYs, dx)
+	ql.plotEnvelope(c, plt, ql.buckets(width))
+}
+
+// plotLine draws the Line as-is, broken at points with a NaN coordinate, which
+// plotter.Line can't draw.
+func (ql *QuantizedLine) plotLine(c draw.Canvas, plt *plot.Plot) {
+	xys := ql.Line.XYs
+	line := *ql.Line
+	start := 0
+	for i := 0; i <= len(xys); i++ {
+		if i < len(xys) && isFinite(xys[i].X) && isFinite(xys[i].Y) {
+			continue
+		}
+		if i > start {
+			line.XYs = xys[start:i]
+			line.Plot(c, plt)
+		}
+		start = i + 1
+	}
+}
+
+// ShouldAggregate reports whether Plot would aggregate the data when drawing
+// onto a canvas of the given width, i.e. whether there are more than
+// PointsPerUnit points per bucket and the X values never decrease. There is
+// one bucket per whole vg.Point of width unless Buckets or Oversample is set.
+// Without Buckets, it is always false for canvases less than 1 vg.Point wide.
+func (ql *QuantizedLine) ShouldAggregate(canvasWidth vg.Length) bool {
+	n := ql.buckets(canvasWidth)
+	if n <= 0 {
+		return false
+	}
+	return float64(ql.Line.XYs.Len()) > float64(n)*ql.pointsPerUnit() && ql.monotonicX()
+}
 
-	slices.Reverse(mins)
+// buckets returns the number of buckets to aggregate into on a canvas of the
+// given width.
+func (ql *QuantizedLine) buckets(canvasWidth vg.Length) int {
+	if ql.Buckets > 0 {
+		return ql.Buckets
+	}
+	return int(canvasWidth) * max(1, ql.Oversample)
+}
 
-	verts := append(maxes, mins...)
+// plotEnvelope aggregates the data into n buckets and draws the bounding lines
+// and the area between them, broken wherever buckets are empty. If there is
+// nothing to aggregate (no data or no buckets) or the X values aren't
+// monotonic, the line is drawn as-is.
+func (ql *QuantizedLine) plotEnvelope(c draw.Canvas, plt *plot.Plot, n int) {
+	if !ql.monotonicX() {
+		ql.plotLine(c, plt)
+		return
+	}
 
-	poly, err := plotter.NewPolygon(verts)
+	e := ql.envelope(n, plt.X.Min, plt.X.Max)
+	if len(e.mins) == 0 {
+		ql.plotLine(c, plt)
+		return
+	}
+	lower, upper := e.bounds(ql.EnvelopeMode, ql.sigmaMultiplier())
+
+	var (
+		polys []*plotter.Polygon
+		err   error
+	)
+	if ql.GradientFill {
+		polys, err = ql.gradientPolygons(lower, upper, e.means, e.segments)
+	} else {
+		var poly *plotter.Polygon
+		poly, err = ql.envelopePolygon(lower, upper, e.segments)
+		polys = []*plotter.Polygon{poly}
+	}
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	r, g, b, a := ql.Line.Color.RGBA()
+	// Polygon clips the envelope to the canvas itself, so fill outside of an
+	// explicitly narrowed axis range follows the canvas edge rather than
+	// leaking past it.
+	for _, poly := range polys {
+		poly.Plot(c, plt)
+	}
+
+	// draw the envelope lines from a copy so the original data is kept intact
+	// for subsequent draws
+	line := *ql.Line
+	bound := line
+	if ql.SolidEnvelope {
+		bound.LineStyle.Dashes = nil
+		bound.LineStyle.DashOffs = 0
+	}
+	for _, seg := range e.segments {
+		bound.XYs = upper[seg[0]:seg[1]]
+		bound.Plot(c, plt)
+		bound.XYs = lower[seg[0]:seg[1]]
+		bound.Plot(c, plt)
+
+		if ql.DrawMean {
+			line.XYs = e.means[seg[0]:seg[1]]
+			line.Plot(c, plt)
+		}
+	}
+}
+
+// BuildPolygon returns the envelope polygon that Plot would fill when drawing
+// onto c, without drawing it, so it can be restyled or reused. The data is
+// aggregated over its own X range, which is also the plot's unless the axis
+// range was set explicitly. If Plot would draw the line as-is instead, the
+// polygon is nil. GradientFill is not reflected: the polygon covers the whole
+// envelope in the flat fill color, where Plot would draw bands.
+func (ql *QuantizedLine) BuildPolygon(c draw.Canvas) (*plotter.Polygon, error) {
+	width := c.Max.X - c.Min.X
+	if !ql.ShouldAggregate(width) {
+		return nil, nil
+	}
+	return ql.buildPolygon(ql.buckets(width))
+}
+
+// buildPolygon aggregates the data over its X range into n buckets and returns
+// the envelope polygon, or nil if there is nothing to aggregate.
+func (ql *QuantizedLine) buildPolygon(n int) (*plotter.Polygon, error) {
+	if !ql.monotonicX() {
+		return nil, nil
+	}
+	xmin, xmax, _, _ := finiteRange(ql.Line.XYs)
+	e := ql.polygon.get(ql.Line.XYs, n, xmin, xmax)
+	if len(e.mins) == 0 {
+		return nil, nil
+	}
+	lower, upper := e.bounds(ql.EnvelopeMode, ql.sigmaMultiplier())
+	return ql.envelopePolygon(lower, upper, e.segments)
+}
+
+// envelopePolygon returns the polygon covering the area between the lower and
+// upper bounds, filled with the fill color and without an outline. Each
+// segment of the envelope is a separate ring, so gaps in the data aren't
+// bridged.
+func (ql *QuantizedLine) envelopePolygon(lower, upper plotter.XYs, segments [][2]int) (*plotter.Polygon, error) {
+	rings := make([]plotter.XYer, len(segments))
+	for i, seg := range segments {
+		// the lines are drawn stepped by the Line itself, but the fill has
+		// to follow the steps explicitly
+		upperFill := stepXYs(upper[seg[0]:seg[1]], ql.Line.StepStyle)
+		lowerFill := stepXYs(lower[seg[0]:seg[1]], ql.Line.StepStyle)
+
+		verts := append(slices.Clone(upperFill), lowerFill...)
+		slices.Reverse(verts[len(upperFill):])
+		rings[i] = verts
+	}
 
-	poly.Color = color.NRGBA64{
-		R: uint16(r),
-		G: uint16(g),
-		B: uint16(b),
-		A: uint16(a / 2),
+	poly, err := plotter.NewPolygon(rings...)
+	if err != nil {
+		return nil, err
 	}
 
+	poly.Color = ql.fillColor()
 	poly.LineStyle.Color = color.Transparent
+	return poly, nil
+}
+
+// envelope returns the data aggregated into n buckets over [xmin, xmax],
+// reusing the previous result if none of those or the XYs slice changed.
+func (ql *QuantizedLine) envelope(n int, xmin, xmax float64) envelope {
+	return ql.cache.get(ql.Line.XYs, n, xmin, xmax)
+}
 
-	poly.Plot(c, plt)
+// get returns xys aggregated into n buckets over [xmin, xmax], from the cache
+// if it was computed from the same arguments.
+func (c *envelopeCache) get(xys plotter.XYs, n int, xmin, xmax float64) envelope {
+	var data *plotter.XY
+	if len(xys) > 0 {
+		data = &xys[0]
+	}
+
+	if c.valid && c.data == data && c.len == len(xys) && c.n == n && c.xmin == xmin && c.xmax == xmax {
+		return c.e
+	}
 
-	ql.Line.XYs = maxes
-	ql.Line.Plot(c, plt)
-	ql.Line.XYs = mins
-	ql.Line.Plot(c, plt)
+	*c = envelopeCache{
+		data:  data,
+		len:   len(xys),
+		n:     n,
+		xmin:  xmin,
+		xmax:  xmax,
+		e:     aggregate(xys, n, xmin, xmax),
+		valid: true,
+	}
+	return c.e
+}
+
+// InvalidateCache discards the envelopes cached by the last aggregated draw
+// and BuildPolygon call.
+// Replacing Line.XYs or changing its length is noticed automatically, but
+// modifying points in place isn't, so call InvalidateCache after doing so.
+func (ql *QuantizedLine) InvalidateCache() {
+	ql.cache = envelopeCache{}
+	ql.polygon = envelopeCache{}
+	ql.monotonic = monotonicCache{}
+}
+
+// monotonicX reports whether the X values of the data never decrease,
+// ignoring NaN and infinite values. The result is cached like the envelope.
+func (ql *QuantizedLine) monotonicX() bool {
+	xys := ql.Line.XYs
+	var data *plotter.XY
+	if len(xys) > 0 {
+		data = &xys[0]
+	}
+
+	c := &ql.monotonic
+	if c.valid && c.data == data && c.len == len(xys) {
+		return c.monotonic
+	}
+
+	monotonic := true
+	prev := math.Inf(-1)
+	for _, p := range xys {
+		if !isFinite(p.X) {
+			continue
+		}
+		if p.X < prev {
+			monotonic = false
+			break
+		}
+		prev = p.X
+	}
+
+	*c = monotonicCache{data: data, len: len(xys), monotonic: monotonic, valid: true}
+	return monotonic
+}
+
+// stepXYs returns xys with the intermediate corner points inserted that a
+// plotter.Line with the given StepStyle would draw between each pair of
+// points.
+func stepXYs(xys plotter.XYs, kind plotter.StepKind) plotter.XYs {
+	if kind == plotter.NoStep || len(xys) < 2 {
+		return xys
+	}
+
+	ret := make(plotter.XYs, 0, 3*len(xys))
+	ret = append(ret, xys[0])
+	for i, p := range xys[1:] {
+		prev := xys[i]
+		switch kind {
+		case plotter.PreStep:
+			ret = append(ret, plotter.XY{X: prev.X, Y: p.Y})
+		case plotter.MidStep:
+			mid := (prev.X + p.X) / 2
+			ret = append(ret, plotter.XY{X: mid, Y: prev.Y}, plotter.XY{X: mid, Y: p.Y})
+		case plotter.PostStep:
+			ret = append(ret, plotter.XY{X: p.X, Y: prev.Y})
+		}
+		ret = append(ret, p)
+	}
+	return ret
+}
+
+// EnvelopeLine is a QuantizedLine that always aggregates its points into a
+// fixed number of buckets, regardless of the number of points or the size of
+// the canvas, unless the X values aren't monotonic. This is useful for vector
+// output, where the canvas size doesn't reflect the final resolution.
+type EnvelopeLine struct {
+	QuantizedLine
+
+	// Buckets is the number of equal-width x-intervals to aggregate the points
+	// into. It shadows QuantizedLine.Buckets, which has no effect here.
+	Buckets int
+}
+
+// NewEnvelopeLine returns an EnvelopeLine for the given points that uses the
+// default line style. Like NewQuantizedLine, it accepts NaN gaps.
+func NewEnvelopeLine(xyer plotter.XYer, buckets int) (*EnvelopeLine, error) {
+	line, err := newGapLine(xyer)
+	if err != nil {
+		return nil, err
+	}
+	return &EnvelopeLine{
+		QuantizedLine: QuantizedLine{Line: line},
+		Buckets:       buckets,
+	}, nil
+}
+
+// Plot draws the min and max lines of the data aggregated into Buckets
+// buckets, with an area fill in between, to a `draw.Canvas`.
+func (el *EnvelopeLine) Plot(c draw.Canvas, plt *plot.Plot) {
+	el.plotEnvelope(c, plt, el.Buckets)
+}
+
+// ShouldAggregate reports whether Plot aggregates the data, which it does
+// whatever the canvas width unless Buckets isn't positive or the X values ever
+// decrease.
+func (el *EnvelopeLine) ShouldAggregate(canvasWidth vg.Length) bool {
+	return el.Buckets > 0 && el.monotonicX()
+}
+
+// BuildPolygon returns the envelope polygon that Plot would fill, aggregated
+// into Buckets buckets over the X range of the data, without drawing it. The
+// canvas is ignored. If there is nothing to aggregate, the polygon is nil. As
+// for QuantizedLine, GradientFill is not reflected.
+func (el *EnvelopeLine) BuildPolygon(c draw.Canvas) (*plotter.Polygon, error) {
+	return el.buildPolygon(el.Buckets)
+}
+
+// DataRange returns the minimum and maximum x and y values of all of the
+// underlying points, regardless of how they are aggregated when drawn,
+// implementing the plot.DataRanger interface. Points in gaps, with a NaN
+// coordinate, are ignored.
+func (ql *QuantizedLine) DataRange() (xmin, xmax, ymin, ymax float64) {
+	return finiteRange(ql.Line.XYs)
+}
+
+// Thumbnail draws a band of the fill color behind a line segment, mirroring
+// the aggregated rendering, implementing the plot.Thumbnailer interface.
+func (ql *QuantizedLine) Thumbnail(c *draw.Canvas) {
+	y := c.Center().Y
+	h := (c.Max.Y - c.Min.Y) / 4
+	c.FillPolygon(ql.fillColor(), []vg.Point{
+		{X: c.Min.X, Y: y - h},
+		{X: c.Min.X, Y: y + h},
+		{X: c.Max.X, Y: y + h},
+		{X: c.Max.X, Y: y - h},
+	})
+
+	if ql.Line.LineStyle.Width != 0 {
+		c.StrokeLine2(ql.Line.LineStyle, c.Min.X, y, c.Max.X, y)
+	}
+}
+
+// pointsPerUnit returns the aggregation threshold in points per vg.Point.
+func (ql *QuantizedLine) pointsPerUnit() float64 {
+	if ql.PointsPerUnit == 0 {
+		return 2
+	}
+	return ql.PointsPerUnit
+}
+
+// sigmaMultiplier returns the width of the StdDev envelope in standard
+// deviations.
+func (ql *QuantizedLine) sigmaMultiplier() float64 {
+	if ql.SigmaMultiplier == 0 {
+		return 1
+	}
+	return ql.SigmaMultiplier
+}
+
+// gradientBands is the number of bands of flat color on each side of the mean
+// that approximate a GradientFill.
+const gradientBands = 8
+
+// gradientPolygons returns the polygons of a GradientFill between the lower and
+// upper bounds, in bands from the center outward on each side, each filled
+// with the alpha interpolated at its middle.
+func (ql *QuantizedLine) gradientPolygons(lower, upper, center plotter.XYs, segments [][2]int) ([]*plotter.Polygon, error) {
+	inner := color.NRGBA64Model.Convert(ql.fillColor()).(color.NRGBA64)
+	edgeOpacity := max(0, min(ql.EdgeOpacity, 1))
+
+	// lerp returns the points the fraction f of the way from center to bound
+	lerp := func(bound plotter.XYs, f float64) plotter.XYs {
+		ret := make(plotter.XYs, len(bound))
+		for i := range ret {
+			ret[i].X = bound[i].X
+			ret[i].Y = center[i].Y + (bound[i].Y-center[i].Y)*f
+		}
+		return ret
+	}
+
+	ret := make([]*plotter.Polygon, 0, 2*gradientBands)
+	for i := 0; i < gradientBands; i++ {
+		f0 := float64(i) / gradientBands
+		f1 := float64(i+1) / gradientBands
+		col := inner
+		col.A = uint16(float64(inner.A) * (1 - (1-edgeOpacity)*(f0+f1)/2))
+
+		for _, bound := range []plotter.XYs{upper, lower} {
+			poly, err := ql.envelopePolygon(lerp(bound, f0), lerp(bound, f1), segments)
+			if err != nil {
+				return nil, err
+			}
+			poly.Color = col
+			ret = append(ret, poly)
+		}
+	}
+	return ret, nil
+}
+
+// fillColor returns the color of the area between the bounding lines.
+func (ql *QuantizedLine) fillColor() color.Color {
+	if ql.FillColor != nil {
+		return ql.FillColor
+	}
+
+	opacity := ql.FillOpacity
+	if opacity == 0 {
+		opacity = 0.5
+	}
+	opacity = max(0, min(opacity, 1))
+
+	c := color.NRGBA64Model.Convert(ql.Line.Color).(color.NRGBA64)
+	c.A = uint16(float64(c.A) * opacity)
+	return c
 }
 
 // SampleBuffer represents a time-series measurement buffer or trace from a test
 // instrument with a fixed sample rate. It implements plotter.XYer using the
-// sample rate to calculate X-values in seconds starting from 0.
+// sample rate to calculate X-values in seconds starting from TimeOffset.
 type SampleBuffer struct {
 	Samples    []float64
 	SampleRate float64 // samples per second
+	StartIndex int     // sample index of Samples[0] (e.g. within a larger file)
+	TimeOffset float64 // time of sample index 0 in seconds
+
+	// IndexMode makes the X-values the sample indices StartIndex+i instead of
+	// times, which is handy for debugging against raw sample positions.
+	IndexMode bool
 }
 
 // Len returns the number of x, y pairs.
@@ -113,70 +769,620 @@ func (s *SampleBuffer) Len() int {
 
 // XY returns an x, y pair.
 func (s *SampleBuffer) XY(i int) (x float64, y float64) {
-	return float64(i) / s.SampleRate, s.Samples[i]
+	if s.IndexMode {
+		return float64(s.StartIndex + i), s.Samples[i]
+	}
+	return s.TimeOffset + float64(s.StartIndex+i)/s.SampleRate, s.Samples[i]
+}
+
+// DataRange returns the time span and the range of sample values of the
+// buffer, ignoring NaN samples, implementing the plot.DataRanger interface.
+// Like plotter.XYRange, it returns an inverted infinite range for an empty
+// buffer.
+func (s *SampleBuffer) DataRange() (xmin, xmax, ymin, ymax float64) {
+	xmin, xmax = math.Inf(1), math.Inf(-1)
+	ymin, ymax = math.Inf(1), math.Inf(-1)
+
+	if len(s.Samples) == 0 {
+		return
+	}
+
+	xmin, _ = s.XY(0)
+	xmax, _ = s.XY(len(s.Samples) - 1)
+
+	for _, v := range s.Samples {
+		if math.IsNaN(v) {
+			continue
+		}
+		ymin = min(ymin, v)
+		ymax = max(ymax, v)
+	}
+
+	return
+}
+
+// SampleWindow is a read-only plotter.XYer view of a range of samples in a
+// SampleBuffer. It shares the backing data of the buffer, and the X values are
+// the same as in the full buffer.
+type SampleWindow struct {
+	buf        *SampleBuffer
+	start, end int
+}
+
+// Window returns a view of samples [start, end) of s without copying them. It
+// panics if the range is out of bounds, like slicing.
+func (s *SampleBuffer) Window(start, end int) *SampleWindow {
+	_ = s.Samples[start:end]
+	return &SampleWindow{buf: s, start: start, end: end}
+}
+
+// Len returns the number of x, y pairs.
+func (w *SampleWindow) Len() int {
+	return w.end - w.start
+}
+
+// XY returns an x, y pair.
+func (w *SampleWindow) XY(i int) (x float64, y float64) {
+	return w.buf.XY(w.start + i)
+}
+
+// MapXYer returns a plotter.XYer that applies f to the Y values of xyer as
+// they are read, leaving the X values unchanged. Nothing is copied, so f is
+// called on every XY call.
+func MapXYer(xyer plotter.XYer, f func(y float64) float64) plotter.XYer {
+	return mappedXYer{xyer, f}
+}
+
+type mappedXYer struct {
+	plotter.XYer
+	f func(float64) float64
+}
+
+func (m mappedXYer) XY(i int) (x float64, y float64) {
+	x, y = m.XYer.XY(i)
+	return x, m.f(y)
+}
+
+// SampleBufferFromXYer returns a SampleBuffer holding the Y values of xyer,
+// which must have at least two points with increasing, uniformly spaced X
+// values. The sample rate is inferred from the spacing, and TimeOffset is the
+// first X value, so the X values are reproduced. It is an error for any X
+// value to be off the uniform grid by more than a millionth of the spacing.
+func SampleBufferFromXYer(xyer plotter.XYer) (*SampleBuffer, error) {
+	n := xyer.Len()
+	if n < 2 {
+		return nil, fmt.Errorf("plotext: can't infer a sample rate from %d points", n)
+	}
+
+	x0, _ := xyer.XY(0)
+	xn, _ := xyer.XY(n - 1)
+	dx := (xn - x0) / float64(n-1)
+	if !(dx > 0) || !isFinite(dx) {
+		return nil, fmt.Errorf("plotext: X values from %g to %g aren't increasing", x0, xn)
+	}
+
+	p := make([]float64, n)
+	for i := range p {
+		var x float64
+		x, p[i] = xyer.XY(i)
+		if math.Abs(x-(x0+float64(i)*dx)) > dx*1e-6 {
+			return nil, fmt.Errorf("plotext: X value %g of point %d is off the uniform spacing %g", x, i, dx)
+		}
+	}
+
+	return &SampleBuffer{
+		Samples:    p,
+		SampleRate: 1 / dx,
+		TimeOffset: x0,
+	}, nil
+}
+
+// startTime returns the X value of the first sample.
+func (s *SampleBuffer) startTime() float64 {
+	return s.TimeOffset + float64(s.StartIndex)/s.SampleRate
 }
 
 // LoadSampleBuffer loads a big-endian binary file containing `size` float64
 // values from disk and constructs a SampleBuffer object with the given sample
-// rate `fs`.
-func LoadSampleBuffer(path string, size int, fs float64) *SampleBuffer {
+// rate `fs`. It is an error for the file to contain fewer than `size` values.
+func LoadSampleBuffer(path string, size int, fs float64) (*SampleBuffer, error) {
 	f, err := os.Open(path)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	defer f.Close()
 
-	p := make([]float64, size)
+	s, err := ReadSampleBuffer(f, size, fs)
+	if err != nil {
+		return nil, fmt.Errorf("plotext: %s: %w", path, err)
+	}
+	return s, nil
+}
 
-	err = binary.Read(f, binary.BigEndian, &p)
+// LoadSampleBufferAll is like LoadSampleBuffer, but reads every sample in the
+// file. It is an error for the file size not to be a multiple of 8 bytes.
+func LoadSampleBufferAll(path string, fs float64) (*SampleBuffer, error) {
+	f, err := os.Open(path)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	size := info.Size()
+	if rem := size % 8; rem != 0 {
+		return nil, fmt.Errorf("plotext: %s: file size %d bytes is not a multiple of 8 (%d bytes left over)", path, size, rem)
+	}
+
+	s, err := ReadSampleBuffer(f, int(size/8), fs)
+	if err != nil {
+		return nil, fmt.Errorf("plotext: %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// LoadSampleBuffers loads a big-endian binary file of float64 values
+// interleaved from `channels` channels (ch0, ch1, ..., ch0, ch1, ...) and
+// returns one SampleBuffer per channel with the given sample rate `fs`. The
+// file must hold exactly `samplesPerChannel` samples for each channel.
+func LoadSampleBuffers(path string, channels, samplesPerChannel int, fs float64) ([]*SampleBuffer, error) {
+	if channels <= 0 {
+		return nil, fmt.Errorf("plotext: invalid channel count %d", channels)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	size := channels * samplesPerChannel
+	if info.Size() != int64(size)*8 {
+		return nil, fmt.Errorf("plotext: %s: file size %d bytes doesn't match %d channels of %d samples (%d bytes)", path, info.Size(), channels, samplesPerChannel, size*8)
+	}
+
+	p, err := readSamples(f, size, binary.BigEndian, 64)
+	if err != nil {
+		return nil, fmt.Errorf("plotext: %s: %w", path, err)
+	}
+
+	ret := make([]*SampleBuffer, channels)
+	for ch := range ret {
+		samples := make([]float64, samplesPerChannel)
+		for i := range samples {
+			samples[i] = p[i*channels+ch]
+		}
+		ret[ch] = &SampleBuffer{
+			Samples:    samples,
+			SampleRate: fs,
+		}
+	}
+
+	return ret, nil
+}
+
+// LoadSampleBufferGzip is like LoadSampleBuffer, but decompresses the file
+// with gzip first. Since the stream isn't seekable, the number of samples must
+// still be given.
+func LoadSampleBufferGzip(path string, size int, fs float64) (*SampleBuffer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("plotext: %s: decompressing: %w", path, err)
+	}
+	defer zr.Close()
+
+	s, err := ReadSampleBuffer(zr, size, fs)
+	if err != nil {
+		return nil, fmt.Errorf("plotext: %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// ReadSampleBuffer is like LoadSampleBuffer, but reads the samples from r.
+func ReadSampleBuffer(r io.Reader, size int, fs float64) (*SampleBuffer, error) {
+	p, err := readSamples(r, size, binary.BigEndian, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SampleBuffer{
+		Samples:    p,
+		SampleRate: fs,
+	}, nil
+}
+
+// LoadSampleBufferWithFormat is like LoadSampleBuffer, but reads IEEE 754
+// floats of the given width `bits` (32 or 64) in the given byte order. 32-bit
+// samples are converted to float64.
+func LoadSampleBufferWithFormat(path string, size int, fs float64, order binary.ByteOrder, bits int) (*SampleBuffer, error) {
+	if bits != 32 && bits != 64 {
+		return nil, fmt.Errorf("plotext: unsupported sample width %d bits", bits)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	p, err := readSamples(f, size, order, bits)
+	if err != nil {
+		return nil, fmt.Errorf("plotext: %s: %w", path, err)
+	}
+
+	return &SampleBuffer{
+		Samples:    p,
+		SampleRate: fs,
+	}, nil
+}
+
+// LoadSampleBufferRange is like LoadSampleBuffer, but only reads the `count`
+// samples starting at sample index `offset` in the file. The StartIndex of the
+// returned SampleBuffer is set to `offset` so that its X values line up with
+// those of the whole file.
+func LoadSampleBufferRange(path string, offset, count int, fs float64) (*SampleBuffer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(int64(offset)*8, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	p, err := readSamples(f, count, binary.BigEndian, 64)
+	if err != nil {
+		return nil, fmt.Errorf("plotext: %s: %w", path, err)
 	}
 
 	return &SampleBuffer{
 		Samples:    p,
 		SampleRate: fs,
+		StartIndex: offset,
+	}, nil
+}
+
+// LoadSampleBufferWithHeader is like LoadSampleBuffer, but skips a header of
+// `headerBytes` bytes at the start of the file before reading the samples.
+func LoadSampleBufferWithHeader(path string, headerBytes int, size int, fs float64) (*SampleBuffer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(int64(headerBytes), io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	s, err := ReadSampleBuffer(f, size, fs)
+	if err != nil {
+		return nil, fmt.Errorf("plotext: %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// WriteTo writes the samples to w as big-endian float64 values, the format
+// read by LoadSampleBuffer, implementing io.WriterTo. The sample rate is not
+// written.
+func (s *SampleBuffer) WriteTo(w io.Writer) (int64, error) {
+	p := make([]byte, len(s.Samples)*8)
+	for i, v := range s.Samples {
+		binary.BigEndian.PutUint64(p[i*8:], math.Float64bits(v))
+	}
+	n, err := w.Write(p)
+	return int64(n), err
+}
+
+// SaveSampleBuffer writes the samples of s to a file at path in the format read
+// by LoadSampleBuffer, replacing the file if it exists.
+func SaveSampleBuffer(path string, s *SampleBuffer) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readSamples reads `size` floats of the given width from r.
+func readSamples(r io.Reader, size int, order binary.ByteOrder, bits int) ([]float64, error) {
+	var err error
+	p := make([]float64, size)
+
+	if bits == 32 {
+		p32 := make([]float32, size)
+		err = binary.Read(r, order, &p32)
+		for i, v := range p32 {
+			p[i] = float64(v)
+		}
+	} else {
+		err = binary.Read(r, order, &p)
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("input is shorter than %d samples (%d bytes)", size, size*bits/8)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// MustLoadSampleBuffer is like LoadSampleBuffer but exits the program with
+// log.Fatal if the file can't be loaded.
+func MustLoadSampleBuffer(path string, size int, fs float64) *SampleBuffer {
+	s, err := LoadSampleBuffer(path, size, fs)
+	if err != nil {
+		log.Fatal(err)
 	}
+	return s
 }
 
+// AutoTicker is a plot.Ticker that chooses power of 10 tick spacing based on
+// the length of the axis.
 type AutoTicker struct {
-	Dim vg.Length
+	Dim     vg.Length // length of the axis; 800 if zero
+	SigFigs int       // minimum significant figures in tick labels; 3 if zero
+	Unit    string    // unit appended to tick labels, e.g. "Hz"
+
+	// MaxLabels, if nonzero, limits the number of labeled major ticks by
+	// increasing the major tick interval so that labels stay on 1, 2, or 5
+	// times a power of 10.
+	MaxLabels int
+
+	// NiceSteps allows minor tick spacings of 2 and 5 times a power of 10 in
+	// addition to powers of 10, choosing whichever is closest to the target
+	// tick pitch.
+	NiceSteps bool
+
+	// LabelFunc, if not nil, formats the labels of major ticks instead of the
+	// default SI formatting.
+	LabelFunc func(value float64) string
+
+	// ForcedMajors are values within the range that always get labeled major
+	// ticks, in addition to the computed ticks.
+	ForcedMajors []float64
+
+	// LabelAll labels every tick instead of only every major tick interval.
+	// It overrides MaxLabels.
+	LabelAll bool
+
+	// SIDigits, if positive, limits the number of decimals of the mantissa of
+	// SI-prefixed labels, after rounding to SigFigs. Trailing zeros are
+	// dropped either way.
+	SIDigits int
+
+	// Anchor is the value that tick positions are multiples of the spacing
+	// away from, so there is always a labeled major tick on it if it's in
+	// range.
+	Anchor float64
+
+	// LabelStyle selects the number format of the default labels.
+	LabelStyle LabelStyle
+
+	// Percent labels ticks as percentages of the data values, e.g. "50%" for
+	// 0.5, while choosing the tick spacing on the data values as usual.
+	// LabelStyle has no effect.
+	Percent bool
+
+	// TickPitch and LabelPitch are the target distances along the axis
+	// between ticks and between labeled major ticks. They are 1/5 inch and 1
+	// inch if zero.
+	TickPitch, LabelPitch vg.Length
+
+	// TrimEdges drops the computed ticks that fall exactly on the ends of the
+	// range, where they would collide with the axis frame. ForcedMajors are
+	// kept.
+	TrimEdges bool
+}
+
+// LabelStyle selects how AutoTicker formats tick labels.
+type LabelStyle int
+
+const (
+	// LabelSI uses SI prefixes, such as "1.5 k", unless all labels are in
+	// [1, 1000).
+	LabelSI LabelStyle = iota
+
+	// LabelEngineering uses a power of 10 that is a multiple of 3, such as
+	// "1.5e3" or "15e3".
+	LabelEngineering
+
+	// LabelScientific uses a mantissa in [1, 10) and a power of 10, such as
+	// "1.5e3" or "1.5e4".
+	LabelScientific
+
+	// LabelPlain writes out the number in full, such as "1500".
+	LabelPlain
+)
+
+// NewAxisTicker returns an AutoTicker whose Dim is the horizontal or vertical
+// extent of c. plot.Ticker.Ticks is only given the data range, so this is the
+// way to match tick density to the size the axis will actually be drawn at.
+func NewAxisTicker(c draw.Canvas, horizontal bool) AutoTicker {
+	if horizontal {
+		return AutoTicker{Dim: c.Max.X - c.Min.X}
+	}
+	return AutoTicker{Dim: c.Max.Y - c.Min.Y}
+}
+
+// Params returns the minor tick spacing and the major tick interval, in minor
+// ticks, that Ticks chooses for the range.
+func (t AutoTicker) Params(min, max float64) (minorSpacing float64, majorInterval int) {
+	minorSpacing, majorInterval, _, _ = t.layout(min, max)
+	return minorSpacing, majorInterval
 }
 
 // Ticks returns Ticks in a specified range
 func (t AutoTicker) Ticks(min float64, max float64) []plot.Tick {
+	sigFigs := t.sigFigs()
+	selectedMinorTickSpacing, selectedMajorTickInterval, minTickIndex, maxTickIndex := t.layout(min, max)
 
+	ret := make([]plot.Tick, 0, maxTickIndex-minTickIndex+1)
+	maxAbs := 0.0
+	anchor := t.Anchor
+	for i := minTickIndex; i <= maxTickIndex; i++ {
+		t := plot.Tick{
+			Value: tickValue(i, selectedMinorTickSpacing),
+		}
+		if anchor != 0 {
+			// drop the rounding error of the addition
+			t.Value = roundSigFigs(anchor+t.Value, 15)
+		}
+
+		if i%selectedMajorTickInterval == 0 {
+			maxAbs = math.Max(maxAbs, math.Abs(t.Value))
+		}
+		ret = append(ret, t)
+	}
+
+	var forced []float64
+	for _, v := range t.ForcedMajors {
+		if v >= min && v <= max {
+			forced = append(forced, v)
+			maxAbs = math.Max(maxAbs, math.Abs(v))
+		}
+	}
+
+	// never round away the digits that tell adjacent major labels apart
+	if n := spacingSigFigs(maxAbs, tickValue(selectedMajorTickInterval, selectedMinorTickSpacing)); n > sigFigs {
+		sigFigs = n
+	}
+
+	// all labels share the SI prefix of the largest one, and get none if it is
+	// in [1, 1000)
+	siExp := siExponent(roundSigFigs(maxAbs, sigFigs))
+
+	for j := range ret {
+		if (minTickIndex+j)%selectedMajorTickInterval != 0 {
+			continue
+		}
+		ret[j].Label = t.formatLabel(ret[j].Value, sigFigs, siExp)
+	}
+
+	// ticks within rounding error of an end are on it
+	tol := selectedMinorTickSpacing * 1e-6
+
+	if t.TrimEdges {
+		ret = slices.DeleteFunc(ret, func(tick plot.Tick) bool {
+			return math.Abs(tick.Value-min) <= tol || math.Abs(tick.Value-max) <= tol
+		})
+	}
+
+	if len(forced) > 0 {
+		// a forced major coinciding with a computed tick just labels it
+		for _, v := range forced {
+			j := slices.IndexFunc(ret, func(tick plot.Tick) bool {
+				return math.Abs(tick.Value-v) <= tol
+			})
+			if j < 0 {
+				ret = append(ret, plot.Tick{Value: v})
+				j = len(ret) - 1
+			}
+			ret[j].Label = t.formatLabel(v, sigFigs, siExp)
+		}
+		slices.SortStableFunc(ret, func(a, b plot.Tick) int {
+			return cmp.Compare(a.Value, b.Value)
+		})
+	}
+
+	return ret
+
+	// return nil
+}
+
+// layout returns the minor tick spacing, the major tick interval in minor
+// ticks, and the range of minor tick indices counted from Anchor that cover
+// the range.
+func (t AutoTicker) layout(min, max float64) (selectedMinorTickSpacing float64, selectedMajorTickInterval, minTickIndex, maxTickIndex int) {
 	dim := t.Dim
 	if dim == 0 {
 		dim = 800
 	}
 
+	sigFigs := t.sigFigs()
+
 	// select an appropriate power of 10 minor tick interval
-	const (
-		targetTickPitch  = font.Inch / 5
+	targetTickPitch := t.TickPitch
+	if targetTickPitch <= 0 {
+		targetTickPitch = font.Inch / 5
+	}
+	targetLabelPitch := t.LabelPitch
+	if targetLabelPitch <= 0 {
 		targetLabelPitch = font.Inch
-	)
+	}
 
 	targetTickCount := float64(dim / targetTickPitch)       // ul
 	targetMinorTickSpacing := (max - min) / targetTickCount // data units
 	// rounded to nearest power of 10
 	selectedMag := math.Round(math.Log10(float64(targetMinorTickSpacing))) // log10 data units
-	selectedMinorTickSpacing := math.Pow10(int(selectedMag))               // data units
-	selectedMinorTickCount := float64(max-min) / selectedMinorTickSpacing  // ul
+	selectedMinorTickSpacing = math.Pow10(int(selectedMag))                // data units
+	if t.NiceSteps {
+		selectedMinorTickSpacing = niceSpacing(targetMinorTickSpacing)
+	}
+	selectedMinorTickCount := float64(max-min) / selectedMinorTickSpacing // ul
 	// selectedMinorTickPitch := dim / vg.Length(selectedMinorTickCount)      // canvas units
 
 	// major ticks at 2, 5, or 10 minor tick intervals to achieve as close to 1 label per inch as possible
 	targetMajorTickCount := float64(dim / targetLabelPitch) // index units
 	targetMajorTickInterval := math.Round(selectedMinorTickCount / targetMajorTickCount)
-	selectedMajorTickInterval := 2
-	if targetMajorTickInterval > 5 {
+	selectedMajorTickInterval = 2
+	if t.NiceSteps {
+		selectedMajorTickInterval = niceMajorTickInterval(spacingMantissa(selectedMinorTickSpacing), selectedMinorTickCount/targetMajorTickCount)
+	} else if targetMajorTickInterval > 5 {
 		selectedMajorTickInterval = 10
 	} else if targetMajorTickInterval > 2 {
 		selectedMajorTickInterval = 5
 	}
 
-	minTickIndex := int(math.Floor(min / selectedMinorTickSpacing))
-	maxTickIndex := int(math.Ceil(max / selectedMinorTickSpacing))
+	minTickIndex, maxTickIndex = tickIndexRange(min-t.Anchor, max-t.Anchor, selectedMinorTickSpacing)
+
+	// a (nearly) flat range can't be subdivided meaningfully, so bracket it
+	// with ticks at the finest spacing the labels can still distinguish
+	if mag := math.Max(math.Abs(min), math.Abs(max)); !(max-min > mag*math.Pow10(-sigFigs)) {
+		selectedMinorTickSpacing = 1
+		if mag > 0 {
+			selectedMinorTickSpacing = math.Pow10(int(math.Floor(math.Log10(mag))) - sigFigs + 1)
+		}
+		selectedMajorTickInterval = 1
+
+		minTickIndex, maxTickIndex = tickIndexRange(min-t.Anchor, max-t.Anchor, selectedMinorTickSpacing)
+		if minTickIndex == maxTickIndex {
+			minTickIndex--
+			maxTickIndex++
+		}
+	}
+
+	// thin out labels on nice multiples until there are few enough
+	if t.MaxLabels > 0 {
+		m := spacingMantissa(selectedMinorTickSpacing)
+		for countMultiples(minTickIndex, maxTickIndex, selectedMajorTickInterval) > t.MaxLabels {
+			selectedMajorTickInterval = nextMajorTickInterval(selectedMajorTickInterval, m)
+		}
+	}
+	if t.LabelAll {
+		selectedMajorTickInterval = 1
+	}
 
 	/*
 		vals := []struct {
@@ -200,23 +1406,267 @@ func (t AutoTicker) Ticks(min float64, max float64) []plot.Tick {
 		}
 	*/
 
-	ret := make([]plot.Tick, 0, maxTickIndex-minTickIndex+1)
-	for i := minTickIndex; i <= maxTickIndex; i++ {
-		t := plot.Tick{
-			Value: float64(i) * selectedMinorTickSpacing,
+	return selectedMinorTickSpacing, selectedMajorTickInterval, minTickIndex, maxTickIndex
+}
+
+// sigFigs returns the number of significant figures in tick labels.
+func (t AutoTicker) sigFigs() int {
+	if t.SigFigs <= 0 {
+		return 3
+	}
+	return t.SigFigs
+}
+
+// spacingSigFigs returns the number of significant figures needed to resolve
+// steps of spacing in values of magnitude up to maxAbs, e.g. 5 for 0.005 steps
+// around 12.3.
+func spacingSigFigs(maxAbs, spacing float64) int {
+	if maxAbs == 0 || !(spacing > 0) {
+		return 1
+	}
+
+	// the decimal place of the last significant digit of spacing
+	digits, exp := decimalDigits(roundSigFigs(spacing, 6))
+	last := exp - digits + 1
+
+	return max(1, int(math.Floor(math.Log10(maxAbs)))-last+1)
+}
+
+// decimalDigits returns the number of significant digits in the shortest
+// decimal representation of v and the power of 10 of the first one.
+func decimalDigits(v float64) (digits, exp int) {
+	m, e, _ := strings.Cut(strconv.FormatFloat(math.Abs(v), 'e', -1, 64), "e")
+	exp, _ = strconv.Atoi(e)
+	return len(strings.Replace(m, ".", "", 1)), exp
+}
+
+// tickIndexRange returns the indices of the multiples of spacing at or just
+// outside of min and max. Quotients within rounding error of an integer are
+// taken as that integer, so that e.g. -4.1/0.1 = -40.99999999999999 doesn't
+// add a tick at -4 beyond the end of the range.
+func tickIndexRange(min, max, spacing float64) (lo, hi int) {
+	return int(math.Floor(snapIndex(min / spacing))), int(math.Ceil(snapIndex(max / spacing)))
+}
+
+// snapIndex returns the tick index quotient q, or the integer it is within
+// rounding error of.
+func snapIndex(q float64) float64 {
+	if r := math.Round(q); math.Abs(q-r) < 1e-9 {
+		return r
+	}
+	return q
+}
+
+// tickValue returns i*spacing as the float64 closest to the exact decimal
+// result, avoiding values like 0.30000000000000004 or 8.999999999999999e+29.
+// It assumes spacing has a short decimal representation (such as a power of
+// 10), so that its reciprocal is an integer when spacing < 1.
+func tickValue(i int, spacing float64) float64 {
+	if spacing < 1 {
+		return float64(i) / math.Round(1/spacing)
+	}
+
+	// the exact product has no more digits than its factors together
+	digits, _ := decimalDigits(spacing)
+	digits += len(strconv.Itoa(i))
+	if i < 0 {
+		digits--
+	}
+	return roundSigFigs(float64(i)*spacing, min(digits, 17))
+}
+
+// GridPositions returns the values of the labeled major ticks and of all ticks
+// that Ticks would return for the range, for drawing major and minor grid
+// lines with different styles.
+func (t AutoTicker) GridPositions(min, max float64) (majors, minors []float64) {
+	for _, tick := range t.Ticks(min, max) {
+		if tick.Label != "" {
+			majors = append(majors, tick.Value)
 		}
+		minors = append(minors, tick.Value)
+	}
+	return majors, minors
+}
 
-		if i%selectedMajorTickInterval == 0 {
-			// todo:
-			// * trim to significant figures
-			// * if largest value is [1, 1000): no suffix
-			// * others: add SI prefix with 3 sigfigs max
-			t.Label = humanize.SI(t.Value, "")
+// NiceRange returns [min, max] expanded outward to the nearest labeled major
+// tick values that an AutoTicker with the given Dim would choose for it, so
+// that the first and last ticks are labeled and flush with the ends of the
+// axis.
+func NiceRange(min, max float64, dim vg.Length) (lo, hi float64) {
+	spacing, interval, _, _ := AutoTicker{Dim: dim}.layout(min, max)
+	step := tickValue(interval, spacing)
+	i, j := tickIndexRange(min, max, step)
+	return tickValue(i, step), tickValue(j, step)
+}
+
+// formatLabel formats a major tick label. LabelSI labels are scaled by
+// 10^siExp and given the matching SI prefix, or none if siExp is 0.
+func (t AutoTicker) formatLabel(value float64, sigFigs int, siExp int) string {
+	if t.LabelFunc != nil {
+		return t.LabelFunc(value)
+	}
+
+	if t.Percent {
+		value *= 100
+	}
+
+	v := roundSigFigs(value, sigFigs)
+	var label string
+	switch {
+	case t.Percent:
+		label = strconv.FormatFloat(v, 'f', -1, 64) + "%"
+	case t.LabelStyle == LabelEngineering:
+		label = formatExp(v, sigFigs, 3)
+	case t.LabelStyle == LabelScientific:
+		label = formatExp(v, sigFigs, 1)
+	case t.LabelStyle == LabelPlain:
+		label = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		if siExp != 0 {
+			return formatSI(v, t.SIDigits, t.Unit, siExp)
 		}
-		ret = append(ret, t)
+		label = strconv.FormatFloat(v, 'f', -1, 64)
 	}
 
-	return ret
+	if t.Unit != "" {
+		label += " " + t.Unit
+	}
+	return label
+}
 
-	// return nil
+// formatExp formats v as a mantissa rounded to sigFigs and a power of 10 that
+// is a multiple of step, e.g. "15e3" for step 3 or "1.5e4" for step 1. The
+// exponent is left out when it is zero.
+func formatExp(v float64, sigFigs, step int) string {
+	if v == 0 {
+		return "0"
+	}
+
+	exp := int(math.Floor(math.Log10(math.Abs(v))))
+	exp = floorDiv(exp, step) * step
+	m := roundSigFigs(v/math.Pow10(exp), sigFigs)
+
+	label := strconv.FormatFloat(m, 'f', -1, 64)
+	if exp != 0 {
+		label += "e" + strconv.Itoa(exp)
+	}
+	return label
+}
+
+// siPrefixes are the SI prefixes for the powers of 1000 from 10^-24 to 10^24.
+var siPrefixes = []string{"y", "z", "a", "f", "p", "n", "µ", "m", "", "k", "M", "G", "T", "P", "E", "Z", "Y"}
+
+// siExponent returns the power of 10, a multiple of 3, of the SI prefix that
+// humanize.SI would choose for v, or 0 if v is 0.
+func siExponent(v float64) int {
+	if v == 0 {
+		return 0
+	}
+	exp := floorDiv(int(math.Floor(math.Log10(math.Abs(v)))), 3) * 3
+	return min(max(exp, -24), 24)
+}
+
+// formatSI formats v scaled by 10^exp with the matching SI prefix and unit,
+// with at most digits decimals in the mantissa if digits is positive. Zero is
+// formatted without a prefix.
+func formatSI(v float64, digits int, unit string, exp int) string {
+	if v == 0 {
+		if unit == "" {
+			return "0"
+		}
+		return "0 " + unit
+	}
+
+	// drop the rounding error of the division
+	m := roundSigFigs(v/math.Pow10(exp), 15)
+	mantissa := humanize.Ftoa(m)
+	if digits > 0 {
+		mantissa = humanize.FtoaWithDigits(m, digits)
+	}
+	return mantissa + " " + siPrefixes[exp/3+8] + unit
+}
+
+// countMultiples returns the number of multiples of n in [lo, hi].
+func countMultiples(lo, hi, n int) int {
+	return floorDiv(hi, n) - floorDiv(lo-1, n)
+}
+
+// floorDiv returns a/b rounded toward negative infinity.
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+// nextMajorTickInterval returns the next major tick interval larger than n
+// such that the major tick spacing stays on 1, 2, or 5 times a power of 10,
+// given the mantissa m (1, 2, or 5) of the minor tick spacing.
+func nextMajorTickInterval(n, m int) int {
+	for v := nextNice(n * m); ; v = nextNice(v) {
+		if v%m == 0 {
+			return v / m
+		}
+	}
+}
+
+// nextNice returns the smallest number in the sequence 1, 2, 5, 10, 20, 50,
+// ... that is larger than v.
+func nextNice(v int) int {
+	mag := 1
+	for v >= 10*mag {
+		mag *= 10
+	}
+	switch {
+	case v < mag:
+		return mag
+	case v < 2*mag:
+		return 2 * mag
+	case v < 5*mag:
+		return 5 * mag
+	default:
+		return 10 * mag
+	}
+}
+
+// niceSpacing returns the number of the form 1, 2, or 5 times a power of 10
+// closest to target on a log scale.
+func niceSpacing(target float64) float64 {
+	base := math.Pow10(int(math.Floor(math.Log10(target))))
+	best := base
+	for _, m := range []float64{2, 5, 10} {
+		if math.Abs(math.Log(m*base/target)) < math.Abs(math.Log(best/target)) {
+			best = m * base
+		}
+	}
+	return best
+}
+
+// spacingMantissa returns the leading digit of a spacing of the form 1, 2, or
+// 5 times a power of 10.
+func spacingMantissa(spacing float64) int {
+	return int(math.Round(spacing / math.Pow10(int(math.Floor(math.Log10(spacing)+1e-9)))))
+}
+
+// niceMajorTickInterval returns the number of minor ticks per major tick
+// closest to target (on a log scale) such that the major tick spacing is 1, 2,
+// or 5 times a power of 10, given the mantissa m of the minor tick spacing.
+func niceMajorTickInterval(m int, target float64) int {
+	best := 0
+	for n := nextMajorTickInterval(1, m); ; n = nextMajorTickInterval(n, m) {
+		if best != 0 && math.Abs(math.Log(float64(n)/target)) >= math.Abs(math.Log(float64(best)/target)) {
+			return best
+		}
+		best = n
+	}
+}
+
+// roundSigFigs rounds v to n significant figures.
+func roundSigFigs(v float64, n int) float64 {
+	r, err := strconv.ParseFloat(strconv.FormatFloat(v, 'g', n, 64), 64)
+	if err != nil {
+		return v
+	}
+	return r
 }
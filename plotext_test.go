@@ -1,13 +1,28 @@
 package plotext
 
 import (
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"image/color"
+	"io"
 	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
 	"slices"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/dustin/go-humanize"
 	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
 	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/recorder"
 )
 
 func TestTicker(t *testing.T) {
@@ -18,7 +33,10 @@ func TestTicker(t *testing.T) {
 		tickSpacing      float64
 		majorInterval    int
 	}{
-		{0, 0, 0, 0, 0, 0, 0},
+		{0, 0, 0, -1, 1, 1, 1},
+		{0, 5, 5, 4.99, 5.01, 0.01, 1},
+		{0, 4.9999, 5.0001, 4.99, 5.01, 0.01, 1},
+		{0, -2e-6, -2e-6, -2.01e-6, -1.99e-6, 0.01e-6, 1},
 		{0, 0, 1, 0, 1, 0.01, 10},
 		{0, -1, 1, -1, 1, 0.1, 2},
 		{100, 0, 0.9, 0, 0.9, 0.1, 10},
@@ -28,10 +46,12 @@ func TestTicker(t *testing.T) {
 		{305, -1, 0, -1, 0, 0.1, 2},
 		{928, -10, 0, -10, 0, 0.1, 10},
 		{1294, -12.6, -5, -12.6, -5, 0.1, 5},
+		{1294, -13.3, -4.1, -13.3, -4.1, 0.1, 5},
+		{1294, 4.1, 13.3, 4.1, 13.3, 0.1, 5},
 	}
 
 	for _, row := range table {
-		dut := AutoTicker{row.dim}
+		dut := AutoTicker{Dim: row.dim}
 		ticks := dut.Ticks(row.min, row.max)
 		ex := expectedTicks(row.tickMin, row.tickMax, row.tickSpacing, row.majorInterval)
 		if !slices.Equal(ticks, ex) {
@@ -43,18 +63,2065 @@ func TestTicker(t *testing.T) {
 	}
 }
 
-func expectedTicks(min, max, spacing float64, interval int) []plot.Tick {
-	if spacing == 0 {
-		return []plot.Tick{{Value: min, Label: humanize.SI(min, "")}}
+func TestTickerLargeMagnitude(t *testing.T) {
+	table := []struct {
+		min, max float64
+		ex       []float64 // a few of the expected tick values
+	}{
+		{0, 1e30, []float64{9e29, 4.1e29, 1e30}},
+		{-5e22, 3e22, []float64{-4.9e22, -3e21, 2.9e22}},
+		{1e20, 1.5e20, []float64{1.23e20, 1.49e20}},
+	}
+
+	for _, row := range table {
+		ticks := AutoTicker{}.Ticks(row.min, row.max)
+		for _, v := range row.ex {
+			if !slices.ContainsFunc(ticks, func(tick plot.Tick) bool { return tick.Value == v }) {
+				t.Errorf("[%g, %g]: no tick at exactly %v in %v", row.min, row.max, v, ticks)
+			}
+		}
 	}
+}
+
+func expectedTicks(min, max, spacing float64, interval int) []plot.Tick {
 	ret := make([]plot.Tick, 0, int((max-min)/spacing))
 
+	maxAbs := 0.0
+	decimals := int(math.Max(0, -math.Floor(math.Log10(spacing))))
 	for i := int(math.Round(min / spacing)); i <= int(math.Round(max/spacing)); i++ {
-		t := plot.Tick{Value: float64(i) * spacing}
+		v, _ := strconv.ParseFloat(strconv.FormatFloat(float64(i)*spacing, 'f', decimals, 64), 64)
+		t := plot.Tick{Value: v}
 		if i%interval == 0 {
-			t.Label = humanize.SI(t.Value, "")
+			t.Label = "major"
+			maxAbs = math.Max(maxAbs, math.Abs(t.Value))
 		}
 		ret = append(ret, t)
 	}
+
+	for i, t := range ret {
+		if t.Label == "" {
+			continue
+		}
+		if maxAbs >= 1 && maxAbs < 1000 {
+			ret[i].Label = strconv.FormatFloat(t.Value, 'g', 3, 64)
+		} else if t.Value == 0 {
+			ret[i].Label = "0"
+		} else {
+			// every label takes the prefix of the largest one
+			scaled, prefix := humanize.ComputeSI(maxAbs)
+			ret[i].Label = humanize.Ftoa(t.Value*scaled/maxAbs) + " " + prefix
+		}
+	}
+	return ret
+}
+
+func TestAggregate(t *testing.T) {
+	var xys plotter.XYs
+
+	// dense cluster in [0, 1)
+	for i := 0; i < 100; i++ {
+		x := float64(i) / 100
+		xys = append(xys, plotter.XY{X: x, Y: math.Sin(2 * math.Pi * x)})
+	}
+
+	// sparse points after a large gap
+	xys = append(xys,
+		plotter.XY{X: 8.5, Y: 5},
+		plotter.XY{X: 9.2, Y: -3},
+		plotter.XY{X: 9.7, Y: 2},
+	)
+
+	e := aggregate(xys, 10, 0, 10)
+
+	exMins := plotter.XYs{{X: 0, Y: -1}, {X: 8.5, Y: 5}, {X: 9.2, Y: -3}}
+	exMaxes := plotter.XYs{{X: 0, Y: 1}, {X: 8.5, Y: 5}, {X: 9.2, Y: 2}}
+	exMeans := plotter.XYs{{X: 0, Y: 0}, {X: 8.5, Y: 5}, {X: 9.2, Y: -0.5}}
+
+	const tol = 1e-3
+	check := func(name string, got, ex plotter.XYs) {
+		if len(got) != len(ex) {
+			t.Errorf("%s: got %d points, expected %d: %v", name, len(got), len(ex), got)
+			return
+		}
+		for i := range got {
+			if math.Abs(got[i].X-ex[i].X) > tol || math.Abs(got[i].Y-ex[i].Y) > tol {
+				t.Errorf("%s[%d]: got %v, expected %v", name, i, got[i], ex[i])
+			}
+		}
+	}
+	check("mins", e.mins, exMins)
+	check("maxes", e.maxes, exMaxes)
+	check("means", e.means, exMeans)
+}
+
+func writeSamples(t *testing.T, order binary.ByteOrder, data any) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "samples.bin")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if err := binary.Write(f, order, data); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadSampleBuffer(t *testing.T) {
+	data := []float64{0, 1.5, -2, 3.25}
+	path := writeSamples(t, binary.BigEndian, data)
+
+	s, err := LoadSampleBuffer(path, len(data), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(s.Samples, data) {
+		t.Errorf("got %v, expected %v", s.Samples, data)
+	}
+	if s.SampleRate != 100 {
+		t.Errorf("got sample rate %f, expected 100", s.SampleRate)
+	}
+
+	if _, err := LoadSampleBuffer(path, len(data)+1, 100); err == nil {
+		t.Error("expected error loading more samples than the file contains")
+	}
+
+	if _, err := LoadSampleBuffer(filepath.Join(t.TempDir(), "missing.bin"), 1, 100); err == nil {
+		t.Error("expected error loading a missing file")
+	}
+}
+
+func TestLoadSampleBufferWithFormat(t *testing.T) {
+	data := []float32{0.5, -1, 2.25, 1e-3}
+	path := writeSamples(t, binary.LittleEndian, data)
+
+	s, err := LoadSampleBufferWithFormat(path, len(data), 1000, binary.LittleEndian, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range data {
+		if s.Samples[i] != float64(v) {
+			t.Errorf("sample %d: got %v, expected %v", i, s.Samples[i], float64(v))
+		}
+	}
+
+	if _, err := LoadSampleBufferWithFormat(path, len(data), 1000, binary.LittleEndian, 16); err == nil {
+		t.Error("expected error for 16-bit samples")
+	}
+}
+
+func TestLoadSampleBufferRange(t *testing.T) {
+	data := make([]float64, 100)
+	for i := range data {
+		data[i] = float64(i) * 2
+	}
+	path := writeSamples(t, binary.BigEndian, data)
+
+	s, err := LoadSampleBufferRange(path, 40, 10, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(s.Samples, data[40:50]) {
+		t.Errorf("got %v, expected %v", s.Samples, data[40:50])
+	}
+	if x, _ := s.XY(0); x != 4 {
+		t.Errorf("got first x %f, expected 4", x)
+	}
+	if x, _ := s.XY(9); x != 4.9 {
+		t.Errorf("got last x %f, expected 4.9", x)
+	}
+
+	if _, err := LoadSampleBufferRange(path, 95, 10, 10); err == nil {
+		t.Error("expected error reading past the end of the file")
+	}
+}
+
+func TestSampleBufferTimeOffset(t *testing.T) {
+	s := &SampleBuffer{
+		Samples:    make([]float64, 11),
+		SampleRate: 10,
+		TimeOffset: 2.5,
+	}
+
+	if x, _ := s.XY(0); x != 2.5 {
+		t.Errorf("got first x %f, expected 2.5", x)
+	}
+	if x, _ := s.XY(10); x != 3.5 {
+		t.Errorf("got last x %f, expected 3.5", x)
+	}
+}
+
+func TestTickerLabels(t *testing.T) {
+	table := []struct {
+		ticker   AutoTicker
+		min, max float64
+		labels   []string
+	}{
+		{
+			// SigFigs is raised to keep adjacent labels distinct
+			AutoTicker{},
+			12.3, 12.34,
+			[]string{"12.3", "12.305", "12.31", "12.315", "12.32", "12.325", "12.33", "12.335", "12.34"},
+		},
+		{
+			AutoTicker{SigFigs: 5},
+			12.3, 12.34,
+			[]string{"12.3", "12.305", "12.31", "12.315", "12.32", "12.325", "12.33", "12.335", "12.34"},
+		},
+		{
+			AutoTicker{SigFigs: 5},
+			-12.34, -12.3,
+			[]string{"-12.34", "-12.335", "-12.33", "-12.325", "-12.32", "-12.315", "-12.31", "-12.305", "-12.3"},
+		},
+		{
+			AutoTicker{SigFigs: 1},
+			0, 1,
+			[]string{"0", "0.1", "0.2", "0.3", "0.4", "0.5", "0.6", "0.7", "0.8", "0.9", "1"},
+		},
+		{
+			AutoTicker{},
+			0, 0.9,
+			[]string{"0", "100 m", "200 m", "300 m", "400 m", "500 m", "600 m", "700 m", "800 m", "900 m"},
+		},
+		{
+			AutoTicker{},
+			0, 950,
+			[]string{"0", "100", "200", "300", "400", "500", "600", "700", "800", "900"},
+		},
+		{
+			AutoTicker{Unit: "Hz"},
+			0, 950,
+			[]string{"0 Hz", "100 Hz", "200 Hz", "300 Hz", "400 Hz", "500 Hz", "600 Hz", "700 Hz", "800 Hz", "900 Hz"},
+		},
+		{
+			AutoTicker{Unit: "Hz"},
+			0, 9000,
+			[]string{"0 Hz", "1 kHz", "2 kHz", "3 kHz", "4 kHz", "5 kHz", "6 kHz", "7 kHz", "8 kHz", "9 kHz"},
+		},
+	}
+
+	for _, row := range table {
+		labels := []string{}
+		for _, tick := range row.ticker.Ticks(row.min, row.max) {
+			if tick.Label != "" {
+				labels = append(labels, tick.Label)
+			}
+		}
+		if !slices.Equal(labels, row.labels) {
+			t.Errorf("%+v [%f, %f]: got labels %q, expected %q", row.ticker, row.min, row.max, labels, row.labels)
+		}
+	}
+}
+
+func TestTickerLabelsDistinct(t *testing.T) {
+	for _, row := range []struct {
+		ticker   AutoTicker
+		min, max float64
+	}{
+		{AutoTicker{}, 1000, 1000.2},
+		{AutoTicker{SigFigs: 1}, 0.5, 0.5004},
+		{AutoTicker{NiceSteps: true}, 12.3, 12.34},
+		{AutoTicker{LabelStyle: LabelScientific}, -98765.4, -98765},
+		{AutoTicker{Unit: "Hz"}, 2e6, 2.0001e6},
+	} {
+		var labels []string
+		for _, tick := range row.ticker.Ticks(row.min, row.max) {
+			if tick.Label != "" {
+				labels = append(labels, tick.Label)
+			}
+		}
+		for i := 1; i < len(labels); i++ {
+			if labels[i] == labels[i-1] {
+				t.Errorf("[%g, %g]: adjacent labels %q repeat in %q", row.min, row.max, labels[i], labels)
+				break
+			}
+		}
+	}
+}
+
+func TestAggregateExported(t *testing.T) {
+	xys := plotter.XYs{
+		{X: 0, Y: 3}, {X: 1, Y: -1}, {X: 2, Y: 4},
+		{X: 3, Y: 1}, {X: 4, Y: 5}, {X: 5, Y: 9},
+		{X: 6, Y: 2}, {X: 7, Y: 6}, {X: 8, Y: 5},
+	}
+
+	mins, maxes := Aggregate(xys, 4)
+
+	exMins := plotter.XYs{{X: 0, Y: -1}, {X: 2, Y: 1}, {X: 4, Y: 5}, {X: 6, Y: 2}}
+	exMaxes := plotter.XYs{{X: 0, Y: 3}, {X: 2, Y: 4}, {X: 4, Y: 9}, {X: 6, Y: 6}}
+
+	if !slices.Equal(mins, exMins) {
+		t.Errorf("mins: got %v, expected %v", mins, exMins)
+	}
+	if !slices.Equal(maxes, exMaxes) {
+		t.Errorf("maxes: got %v, expected %v", maxes, exMaxes)
+	}
+}
+
+func TestQuantizedLineFillColor(t *testing.T) {
+	table := []struct {
+		opacity float64
+		alpha   uint16
+	}{
+		{0, 0x7fff},
+		{0.25, 0x3fff},
+		{1, 0xffff},
+		{2, 0xffff},
+		{-1, 0},
+	}
+
+	for _, row := range table {
+		ql := &QuantizedLine{
+			Line:        &plotter.Line{LineStyle: draw.LineStyle{Color: color.NRGBA{R: 255, A: 255}}},
+			FillOpacity: row.opacity,
+		}
+		ex := color.NRGBA64{R: 0xffff, A: row.alpha}
+		if c := ql.fillColor(); c != ex {
+			t.Errorf("opacity %f: got fill color %v, expected %v", row.opacity, c, ex)
+		}
+	}
+}
+
+func TestQuantizedLineDataRange(t *testing.T) {
+	s := &SampleBuffer{Samples: make([]float64, 10000), SampleRate: 1000}
+	for i := range s.Samples {
+		s.Samples[i] = math.Sin(float64(i) / 100)
+	}
+	s.Samples[1234] = 7
+	s.Samples[5678] = -3
+
+	line, err := plotter.NewLine(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := plot.New()
+	p.Add(&QuantizedLine{Line: line})
+
+	if p.X.Min != 0 || p.X.Max != 9.999 {
+		t.Errorf("got x range [%f, %f], expected [0, 9.999]", p.X.Min, p.X.Max)
+	}
+	if p.Y.Min != -3 || p.Y.Max != 7 {
+		t.Errorf("got y range [%f, %f], expected [-3, 7]", p.Y.Min, p.Y.Max)
+	}
+}
+
+func TestNewQuantizedLine(t *testing.T) {
+	s := &SampleBuffer{Samples: []float64{0, 1, 2, 3}, SampleRate: 1}
+
+	ql, err := NewQuantizedLine(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ql.Line.XYs.Len() != s.Len() {
+		t.Errorf("got %d points, expected %d", ql.Line.XYs.Len(), s.Len())
+	}
+	if ql.Line.Width != plotter.DefaultLineStyle.Width {
+		t.Errorf("got line width %v, expected default %v", ql.Line.Width, plotter.DefaultLineStyle.Width)
+	}
+
+	// NaN is a gap, but infinities are rejected like plotter.NewLine does
+	s.Samples[2] = math.NaN()
+	if _, err := NewQuantizedLine(s); err != nil {
+		t.Errorf("got error %v for a NaN gap", err)
+	}
+	if _, err := NewEnvelopeLine(s, 10); err != nil {
+		t.Errorf("got error %v for a NaN gap in an EnvelopeLine", err)
+	}
+	s.Samples[2] = math.Inf(1)
+	_, exErr := plotter.NewLine(s)
+	if _, err := NewQuantizedLine(s); err != exErr {
+		t.Errorf("got error %v, expected %v", err, exErr)
+	}
+}
+
+func TestQuantizedLineNaNGap(t *testing.T) {
+	s := sineBuffer(10000, 1000, 3, 1)
+	for i := 4000; i < 6000; i++ {
+		s.Samples[i] = math.NaN()
+	}
+
+	p, err := QuickPlot([]*SampleBuffer{s})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.X.Min != 0 || p.X.Max != 9.999 || p.Y.Min < -1.01 || p.Y.Max > 1.01 {
+		t.Errorf("got range x [%v, %v], y [%v, %v] around the gap", p.X.Min, p.X.Max, p.Y.Min, p.Y.Max)
+	}
+
+	// drawn raw, the line stops either side of the gap
+	ql, err := NewQuantizedLine(s.Decimate(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := plot.New()
+	raw.X.Min, raw.X.Max, raw.Y.Min, raw.Y.Max = 0, 10, -1, 1
+	rec := new(recorder.Canvas)
+	ql.Plot(draw.NewCanvas(rec, 1000, 100), raw)
+	strokes := 0
+	for _, a := range rec.Actions {
+		if _, ok := a.(*recorder.Stroke); ok {
+			strokes++
+		}
+	}
+	if strokes != 2 {
+		t.Errorf("got %d strokes, expected one each side of the gap", strokes)
+	}
+
+	w, err := p.WriterTo(200, 100, "png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.WriteTo(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSampleWindow(t *testing.T) {
+	s := &SampleBuffer{Samples: make([]float64, 100), SampleRate: 10}
+	for i := range s.Samples {
+		s.Samples[i] = float64(i)
+	}
+
+	w := s.Window(20, 30)
+	if w.Len() != 10 {
+		t.Errorf("got length %d, expected 10", w.Len())
+	}
+	if x, y := w.XY(0); x != 2 || y != 20 {
+		t.Errorf("got first point (%f, %f), expected (2, 20)", x, y)
+	}
+
+	// the window shares the buffer's data
+	s.Samples[25] = -1
+	if _, y := w.XY(5); y != -1 {
+		t.Errorf("got %f, expected window to reflect buffer contents", y)
+	}
+}
+
+func TestAggregateNaN(t *testing.T) {
+	nan := math.NaN()
+	xys := plotter.XYs{
+		{X: 0, Y: 1}, {X: 0.5, Y: nan}, {X: 0.7, Y: -2},
+		{X: 1, Y: nan}, {X: 1.5, Y: nan},
+		{X: 2, Y: math.Inf(1)}, {X: 2.5, Y: 4}, {X: 2.9, Y: 3},
+	}
+
+	e := aggregate(xys, 3, 0, 3)
+	mins, maxes := e.mins, e.maxes
+
+	exMins := plotter.XYs{{X: 0, Y: -2}, {X: 2.5, Y: 3}}
+	exMaxes := plotter.XYs{{X: 0, Y: 1}, {X: 2.5, Y: 4}}
+
+	if !slices.Equal(mins, exMins) {
+		t.Errorf("mins: got %v, expected %v", mins, exMins)
+	}
+	if !slices.Equal(maxes, exMaxes) {
+		t.Errorf("maxes: got %v, expected %v", maxes, exMaxes)
+	}
+}
+
+func TestAggregateExportedNonFinite(t *testing.T) {
+	// non-finite points must not widen the range the buckets cover
+	for _, bad := range []plotter.XY{{X: math.NaN(), Y: 5}, {X: math.Inf(1), Y: 5}, {X: math.Inf(-1), Y: 5}} {
+		xys := plotter.XYs{{X: 0, Y: 1}, {X: 1, Y: 2}, bad, {X: 2, Y: 3}, {X: 3, Y: 4}}
+		mins, maxes := Aggregate(xys, 3)
+
+		exMins := plotter.XYs{{X: 0, Y: 1}, {X: 1, Y: 2}, {X: 2, Y: 3}}
+		exMaxes := plotter.XYs{{X: 0, Y: 1}, {X: 1, Y: 2}, {X: 2, Y: 4}}
+		if !slices.Equal(mins, exMins) {
+			t.Errorf("%v: mins: got %v, expected %v", bad, mins, exMins)
+		}
+		if !slices.Equal(maxes, exMaxes) {
+			t.Errorf("%v: maxes: got %v, expected %v", bad, maxes, exMaxes)
+		}
+	}
+}
+
+func TestSaveSampleBuffer(t *testing.T) {
+	s := sineBuffer(1000, 1000, 3, 1)
+
+	var buf bytes.Buffer
+	n, err := s.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(s.Samples)*8) || buf.Len() != len(s.Samples)*8 {
+		t.Errorf("got %d bytes written (%d in buffer), expected %d", n, buf.Len(), len(s.Samples)*8)
+	}
+
+	path := filepath.Join(t.TempDir(), "samples.bin")
+	if err := SaveSampleBuffer(path, s); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadSampleBuffer(path, len(s.Samples), s.SampleRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(loaded.Samples, s.Samples) {
+		t.Error("loaded samples differ from saved samples")
+	}
+}
+
+func TestReadSampleBuffer(t *testing.T) {
+	data := []float64{1, -2, 3.5}
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, data)
+
+	s, err := ReadSampleBuffer(&buf, len(data), 48000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(s.Samples, data) {
+		t.Errorf("got %v, expected %v", s.Samples, data)
+	}
+	if s.SampleRate != 48000 {
+		t.Errorf("got sample rate %f, expected 48000", s.SampleRate)
+	}
+
+	if _, err := ReadSampleBuffer(bytes.NewReader(make([]byte, 12)), 2, 48000); err == nil {
+		t.Error("expected error reading a partial sample")
+	}
+}
+
+func TestLoadSampleBufferAll(t *testing.T) {
+	data := []float64{4, 3, 2, 1, 0}
+	path := writeSamples(t, binary.BigEndian, data)
+
+	s, err := LoadSampleBufferAll(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(s.Samples, data) {
+		t.Errorf("got %v, expected %v", s.Samples, data)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{1, 2, 3})
+	f.Close()
+
+	_, err = LoadSampleBufferAll(path, 10)
+	if err == nil {
+		t.Fatal("expected error loading a file with a partial sample")
+	}
+	if msg := err.Error(); !strings.Contains(msg, "43 bytes") || !strings.Contains(msg, "3 bytes left over") {
+		t.Errorf("error %q doesn't report the file size and remainder", msg)
+	}
+}
+
+func TestTickerMaxLabels(t *testing.T) {
+	table := []struct {
+		dim       vg.Length
+		min, max  float64
+		maxLabels int
+	}{
+		{20, 0, 1000, 3},
+		{20, -1000, 1000, 2},
+		{50, -3.7, 12.1, 4},
+		{800, 0, 1, 5},
+		{800, 0, 1, 1},
+	}
+
+	for _, row := range table {
+		dut := AutoTicker{Dim: row.dim, MaxLabels: row.maxLabels}
+		ticks := dut.Ticks(row.min, row.max)
+
+		var labels []float64
+		for _, tick := range ticks {
+			if tick.Label != "" {
+				labels = append(labels, tick.Value)
+			}
+		}
+		if len(labels) == 0 || len(labels) > row.maxLabels {
+			t.Errorf("%+v [%f, %f]: got %d labels, expected 1 to %d", dut, row.min, row.max, len(labels), row.maxLabels)
+			continue
+		}
+
+		// labels must still fall on 1, 2, or 5 times a power of 10
+		if len(labels) > 1 {
+			step := labels[1] - labels[0]
+			mantissa := step / math.Pow10(int(math.Floor(math.Log10(step)+1e-9)))
+			if math.Abs(mantissa-1) > 1e-6 && math.Abs(mantissa-2) > 1e-6 && math.Abs(mantissa-5) > 1e-6 {
+				t.Errorf("%+v [%f, %f]: got label step %f, expected a nice number", dut, row.min, row.max, step)
+			}
+		}
+	}
+
+	intervals := []struct{ n, m, ex int }{
+		{2, 1, 5}, {5, 1, 10}, {10, 1, 20}, {20, 1, 50}, {50, 1, 100}, {100, 1, 200},
+		{5, 2, 10}, {10, 2, 25}, {25, 2, 50},
+		{2, 5, 4}, {4, 5, 10}, {10, 5, 20},
+	}
+	for _, row := range intervals {
+		if got := nextMajorTickInterval(row.n, row.m); got != row.ex {
+			t.Errorf("nextMajorTickInterval(%d, %d): got %d, expected %d", row.n, row.m, got, row.ex)
+		}
+	}
+}
+
+func TestTickerLabelFunc(t *testing.T) {
+	dut := AutoTicker{
+		LabelFunc: func(v float64) string {
+			return fmt.Sprintf("%.1f kΩ", v)
+		},
+	}
+
+	ticks := dut.Ticks(0, 1)
+	ex := expectedTicks(0, 1, 0.01, 10)
+	for i := range ex {
+		if ex[i].Label != "" {
+			ex[i].Label = fmt.Sprintf("%.1f kΩ", ex[i].Value)
+		}
+	}
+
+	if !slices.Equal(ticks, ex) {
+		t.Errorf("got: %v", ticks)
+		t.Errorf("expected: %v", ex)
+	}
+}
+
+func TestTickerValues(t *testing.T) {
+	dut := AutoTicker{Dim: 100}
+	ex := []float64{0, 0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1}
+
+	var values []float64
+	for _, tick := range dut.Ticks(0, 1) {
+		values = append(values, tick.Value)
+	}
+	if !slices.Equal(values, ex) {
+		t.Errorf("got values %v, expected %v", values, ex)
+	}
+}
+
+// filledPaths returns the paths filled with the given color on a recorded
+// canvas.
+func filledPaths(rec *recorder.Canvas, col color.Color) []vg.Path {
+	var (
+		ret []vg.Path
+		cur color.Color
+	)
+	for _, a := range rec.Actions {
+		switch a := a.(type) {
+		case *recorder.SetColor:
+			cur = a.Color
+		case *recorder.Fill:
+			if cur == col {
+				ret = append(ret, a.Path)
+			}
+		}
+	}
 	return ret
 }
+
+// pathVertices returns the number of distinct vertices in a closed path.
+func pathVertices(path vg.Path) int {
+	n := 0
+	for _, comp := range path {
+		if comp.Type == vg.MoveComp || comp.Type == vg.LineComp {
+			n++
+		}
+	}
+	return n
+}
+
+func TestEnvelopeLine(t *testing.T) {
+	s := sineBuffer(10000, 1000, 5, 1)
+
+	for _, buckets := range []int{10, 50, 200} {
+		el, err := NewEnvelopeLine(s, buckets)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		p := plot.New()
+		p.Add(el)
+
+		rec := new(recorder.Canvas)
+		p.Draw(draw.NewCanvas(rec, 4*vg.Inch, 3*vg.Inch))
+
+		polys := filledPaths(rec, el.fillColor())
+		if len(polys) != 1 {
+			t.Errorf("%d buckets: got %d filled polygons, expected 1", buckets, len(polys))
+			continue
+		}
+		if n := pathVertices(polys[0]); n != 2*buckets {
+			t.Errorf("%d buckets: got %d polygon vertices, expected %d", buckets, n, 2*buckets)
+		}
+	}
+
+	// the decision doesn't depend on the canvas or QuantizedLine.Buckets
+	el, err := NewEnvelopeLine(sineBuffer(100000, 1000, 5, 1), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, width := range []vg.Length{0, 100, 10000} {
+		if !el.ShouldAggregate(width) {
+			t.Errorf("width %v: ShouldAggregate is false, but Plot aggregates", width)
+		}
+	}
+	el.Buckets = 0
+	if el.ShouldAggregate(100) {
+		t.Error("ShouldAggregate is true without buckets")
+	}
+	el.Buckets = 10
+	el.Line.XYs[5].X = -1
+	el.InvalidateCache()
+	if el.ShouldAggregate(100) {
+		t.Error("ShouldAggregate is true for non-monotonic X")
+	}
+}
+
+func TestQuantizedLineThumbnail(t *testing.T) {
+	ql, err := NewQuantizedLine(plotter.XYs{{X: 0, Y: 0}, {X: 1, Y: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := new(recorder.Canvas)
+	c := draw.NewCanvas(rec, 20, 10)
+	ql.Thumbnail(&c)
+
+	polys := filledPaths(rec, ql.fillColor())
+	if len(polys) != 1 {
+		t.Fatalf("got %d filled swatches, expected 1", len(polys))
+	}
+	for _, comp := range polys[0] {
+		if comp.Type == vg.CloseComp {
+			continue
+		}
+		if comp.Pos.X < c.Min.X || comp.Pos.X > c.Max.X || comp.Pos.Y < c.Min.Y || comp.Pos.Y > c.Max.Y {
+			t.Errorf("swatch vertex %v is outside of the canvas %v", comp.Pos, c.Rectangle)
+		}
+	}
+}
+
+func TestLoadSampleBuffers(t *testing.T) {
+	data := []float64{0, 10, 1, 11, 2, 12, 3, 13}
+	path := writeSamples(t, binary.BigEndian, data)
+
+	bufs, err := LoadSampleBuffers(path, 2, 4, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bufs) != 2 {
+		t.Fatalf("got %d channels, expected 2", len(bufs))
+	}
+	for ch, ex := range [][]float64{{0, 1, 2, 3}, {10, 11, 12, 13}} {
+		if !slices.Equal(bufs[ch].Samples, ex) {
+			t.Errorf("channel %d: got %v, expected %v", ch, bufs[ch].Samples, ex)
+		}
+		if bufs[ch].SampleRate != 50 {
+			t.Errorf("channel %d: got sample rate %f, expected 50", ch, bufs[ch].SampleRate)
+		}
+	}
+
+	if _, err := LoadSampleBuffers(path, 2, 3, 50); err == nil {
+		t.Error("expected error for mismatched file length")
+	}
+	if _, err := LoadSampleBuffers(path, 3, 3, 50); err == nil {
+		t.Error("expected error for mismatched file length")
+	}
+}
+
+func TestQuantizedLinePointsPerUnit(t *testing.T) {
+	// 100 points wide
+	const width = 100
+
+	table := []struct {
+		points        int
+		pointsPerUnit float64
+		aggregated    bool
+	}{
+		{200, 0, false},
+		{201, 0, true},
+		{201, 3, false},
+		{301, 3, true},
+		{100, 0.5, true},
+		{50, 0.5, false},
+	}
+
+	for _, row := range table {
+		ql, err := NewQuantizedLine(sineBuffer(row.points, 100, 1, 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ql.PointsPerUnit = row.pointsPerUnit
+
+		p := plot.New()
+		p.X.Min, p.X.Max = 0, float64(row.points)/100
+		p.Y.Min, p.Y.Max = -1, 1
+
+		rec := new(recorder.Canvas)
+		ql.Plot(draw.NewCanvas(rec, width, width), p)
+
+		aggregated := len(filledPaths(rec, ql.fillColor())) > 0
+		if aggregated != row.aggregated {
+			t.Errorf("%d points at %f points per unit: got aggregated=%t, expected %t", row.points, row.pointsPerUnit, aggregated, row.aggregated)
+		}
+	}
+}
+
+func TestQuantizedLineShouldAggregate(t *testing.T) {
+	table := []struct {
+		points        int
+		width         vg.Length
+		pointsPerUnit float64
+		aggregate     bool
+	}{
+		{199, 100, 0, false},
+		{200, 100, 0, false},
+		{201, 100, 0, true},
+		{201, 100.9, 0, true},
+		{202, 101, 0, false},
+		{400, 100, 4, false},
+		{401, 100, 4, true},
+		{1, 0, 0, false},
+		{1000, 0.5, 0, false},
+		{0, 0, 0, false},
+	}
+
+	for _, row := range table {
+		ql := &QuantizedLine{
+			Line:          &plotter.Line{XYs: make(plotter.XYs, row.points)},
+			PointsPerUnit: row.pointsPerUnit,
+		}
+		if got := ql.ShouldAggregate(row.width); got != row.aggregate {
+			t.Errorf("%d points, width %v, %f points per unit: got %t, expected %t", row.points, row.width, row.pointsPerUnit, got, row.aggregate)
+		}
+	}
+}
+
+func TestTickerNiceSteps(t *testing.T) {
+	table := []struct {
+		dim              vg.Length
+		min, max         float64
+		tickMin, tickMax float64
+		tickSpacing      float64
+		majorInterval    int
+	}{
+		{100, 0, 3, 0, 3, 0.5, 4},
+		{800, 0, 3, 0, 3, 0.05, 4},
+		{300, 0, 40, 0, 40, 2, 5},
+		// powers of 10 are still chosen when they're closest
+		{100, 0, 0.9, 0, 0.9, 0.1, 5},
+	}
+
+	for _, row := range table {
+		dut := AutoTicker{Dim: row.dim, NiceSteps: true}
+		ticks := dut.Ticks(row.min, row.max)
+		ex := expectedTicks(row.tickMin, row.tickMax, row.tickSpacing, row.majorInterval)
+		if !slices.Equal(ticks, ex) {
+			t.Error("---")
+			t.Errorf("input: dim=%f min=%f max=%f", row.dim, row.min, row.max)
+			t.Errorf("got: %v", ticks)
+			t.Errorf("expected: %v", ex)
+		}
+	}
+}
+
+func TestQuantizedLineClipping(t *testing.T) {
+	ql, err := NewQuantizedLine(sineBuffer(10000, 1000, 20, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// axis range narrower than the data
+	p := plot.New()
+	p.X.Min, p.X.Max = 0, 10
+	p.Y.Min, p.Y.Max = -0.5, 0.5
+
+	const size = 100
+	rec := new(recorder.Canvas)
+	c := draw.NewCanvas(rec, size, size)
+	ql.Plot(c, p)
+
+	polys := filledPaths(rec, ql.fillColor())
+	if len(polys) == 0 {
+		t.Fatal("expected envelope to be drawn")
+	}
+
+	onEdge := 0
+	for _, comp := range polys[0] {
+		if comp.Type == vg.CloseComp {
+			continue
+		}
+		if comp.Pos.Y < c.Min.Y || comp.Pos.Y > c.Max.Y {
+			t.Errorf("envelope vertex %v is outside of the canvas %v", comp.Pos, c.Rectangle)
+		}
+		if comp.Pos.Y == c.Min.Y || comp.Pos.Y == c.Max.Y {
+			onEdge++
+		}
+	}
+	if onEdge == 0 {
+		t.Error("expected envelope to follow the canvas edge where it is clipped")
+	}
+}
+
+func TestLoadSampleBufferWithHeader(t *testing.T) {
+	data := []float64{1, 2, 3}
+
+	var buf bytes.Buffer
+	buf.WriteString("CAPT")
+	binary.Write(&buf, binary.BigEndian, uint32(len(data)))
+	binary.Write(&buf, binary.BigEndian, 1e6)
+	binary.Write(&buf, binary.BigEndian, data)
+
+	path := filepath.Join(t.TempDir(), "capture.bin")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadSampleBufferWithHeader(path, 16, len(data), 1e6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(s.Samples, data) {
+		t.Errorf("got %v, expected %v", s.Samples, data)
+	}
+}
+
+func TestEnvelopeStdDev(t *testing.T) {
+	// one bucket of zeros with a single huge outlier
+	xys := make(plotter.XYs, 100)
+	for i := range xys {
+		xys[i].X = float64(i)
+	}
+	xys[50].Y = 100
+
+	e := aggregate(xys, 1, 0, 100)
+
+	lower, upper := e.bounds(MinMax, 1)
+	if lower[0].Y != 0 || upper[0].Y != 100 {
+		t.Errorf("min/max: got [%f, %f], expected [0, 100]", lower[0].Y, upper[0].Y)
+	}
+
+	// mean 1, standard deviation sqrt(99)
+	sigma := math.Sqrt(99)
+	for _, k := range []float64{1, 2} {
+		lower, upper = e.bounds(StdDev, k)
+		if exLo, exHi := 1-k*sigma, 1+k*sigma; math.Abs(lower[0].Y-exLo) > 1e-9 || math.Abs(upper[0].Y-exHi) > 1e-9 {
+			t.Errorf("std dev (k=%f): got [%f, %f], expected [%f, %f]", k, lower[0].Y, upper[0].Y, exLo, exHi)
+		}
+		if upper[0].Y >= 100 {
+			t.Errorf("std dev (k=%f): expected band to be narrower than the min/max envelope", k)
+		}
+	}
+}
+
+func TestSampleBufferDataRange(t *testing.T) {
+	s := &SampleBuffer{
+		Samples:    []float64{1, math.NaN(), -4, 2.5, 3, math.NaN()},
+		SampleRate: 2,
+		TimeOffset: 10,
+	}
+
+	xmin, xmax, ymin, ymax := s.DataRange()
+	if xmin != 10 || xmax != 12.5 || ymin != -4 || ymax != 3 {
+		t.Errorf("got range x=[%f, %f] y=[%f, %f], expected x=[10, 12.5] y=[-4, 3]", xmin, xmax, ymin, ymax)
+	}
+}
+
+func TestQuantizedLineExplicitFillColor(t *testing.T) {
+	ql, err := NewQuantizedLine(sineBuffer(10000, 1000, 20, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fill := color.NRGBA{R: 10, G: 200, B: 30, A: 100}
+	ql.FillColor = fill
+	ql.FillOpacity = 0.9
+
+	if c := ql.fillColor(); c != fill {
+		t.Errorf("got fill color %v, expected %v", c, fill)
+	}
+
+	p := plot.New()
+	p.X.Min, p.X.Max = 0, 10
+	p.Y.Min, p.Y.Max = -1, 1
+
+	rec := new(recorder.Canvas)
+	ql.Plot(draw.NewCanvas(rec, 100, 100), p)
+
+	if len(filledPaths(rec, fill)) != 1 {
+		t.Error("expected envelope to be filled with FillColor")
+	}
+}
+
+func TestAggregateAll(t *testing.T) {
+	xyers := make([]plotter.XYer, 16)
+	for i := range xyers {
+		xyers[i] = sineBuffer(1000+i*100, 1000, float64(i+1), float64(i))
+	}
+
+	mins, maxes := AggregateAll(xyers, 50)
+	if len(mins) != len(xyers) || len(maxes) != len(xyers) {
+		t.Fatalf("got %d, %d results, expected %d", len(mins), len(maxes), len(xyers))
+	}
+
+	for i, xyer := range xyers {
+		exMins, exMaxes := Aggregate(xyer, 50)
+		if !slices.Equal(mins[i], exMins) || !slices.Equal(maxes[i], exMaxes) {
+			t.Errorf("result %d differs from sequential aggregation", i)
+		}
+	}
+}
+
+func benchmarkBuffers() []plotter.XYer {
+	// the same data for every channel keeps the benchmark's memory use down
+	s := sineBuffer(1_000_000, 1e6, 1000, 1)
+	xyers := make([]plotter.XYer, 32)
+	for i := range xyers {
+		xyers[i] = s
+	}
+	return xyers
+}
+
+func BenchmarkAggregateSequential(b *testing.B) {
+	xyers := benchmarkBuffers()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		for _, xyer := range xyers {
+			Aggregate(xyer, 1000)
+		}
+	}
+}
+
+func BenchmarkAggregateAll(b *testing.B) {
+	xyers := benchmarkBuffers()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		AggregateAll(xyers, 1000)
+	}
+}
+
+func TestAggregateEmpty(t *testing.T) {
+	xys := plotter.XYs{{X: 0, Y: 1}, {X: 1, Y: 2}}
+
+	for _, n := range []int{0, -1} {
+		if e := aggregate(xys, n, 0, 1); len(e.mins) != 0 || len(e.maxes) != 0 {
+			t.Errorf("%d buckets: got %v, %v, expected no points", n, e.mins, e.maxes)
+		}
+	}
+
+	if mins, maxes := Aggregate(plotter.XYs{}, 10); len(mins) != 0 || len(maxes) != 0 {
+		t.Errorf("empty data: got %v, %v, expected no points", mins, maxes)
+	}
+
+	p := plot.New()
+	p.X.Min, p.X.Max = 0, 1
+	p.Y.Min, p.Y.Max = 0, 1
+
+	// zero-width canvas
+	ql := &QuantizedLine{Line: &plotter.Line{XYs: xys, LineStyle: plotter.DefaultLineStyle}}
+	ql.Plot(draw.NewCanvas(new(recorder.Canvas), 0, 100), p)
+
+	// empty data with forced aggregation
+	el := &EnvelopeLine{QuantizedLine: QuantizedLine{Line: &plotter.Line{}}, Buckets: 10}
+	el.Plot(draw.NewCanvas(new(recorder.Canvas), 100, 100), p)
+
+	el.Buckets = 0
+	el.Line.XYs = xys
+	el.Plot(draw.NewCanvas(new(recorder.Canvas), 100, 100), p)
+}
+
+func TestTickerForcedMajors(t *testing.T) {
+	dut := AutoTicker{Dim: 100, ForcedMajors: []float64{0.35, 0.7, 5}}
+	ticks := dut.Ticks(0, 1)
+
+	ex := expectedTicks(0, 1, 0.1, 10)
+	ex[7].Label = "0.7"
+	ex = slices.Insert(ex, 4, plot.Tick{Value: 0.35, Label: "0.35"})
+
+	if !slices.Equal(ticks, ex) {
+		t.Errorf("got: %v", ticks)
+		t.Errorf("expected: %v", ex)
+	}
+}
+
+func TestTickerGridPositions(t *testing.T) {
+	table := []struct {
+		dim      vg.Length
+		min, max float64
+		interval int
+	}{
+		{123, 0, 1.5, 10},
+		{305, -1, 0, 2},
+		{1294, -12.6, -5, 5},
+	}
+
+	for _, row := range table {
+		majors, minors := AutoTicker{Dim: row.dim}.GridPositions(row.min, row.max)
+		if len(majors) == 0 {
+			t.Errorf("[%f, %f]: got no major positions", row.min, row.max)
+			continue
+		}
+
+		first := slices.Index(minors, majors[0])
+		if first < 0 {
+			t.Errorf("[%f, %f]: major %f is not a minor position", row.min, row.max, majors[0])
+			continue
+		}
+		for i, v := range majors {
+			j := first + i*row.interval
+			if j >= len(minors) || minors[j] != v {
+				t.Errorf("[%f, %f]: major %d (%f) is not every %d minors", row.min, row.max, i, v, row.interval)
+			}
+		}
+	}
+}
+
+func TestStepXYs(t *testing.T) {
+	xys := plotter.XYs{{X: 0, Y: 0}, {X: 1, Y: 2}, {X: 3, Y: 1}}
+
+	table := []struct {
+		kind plotter.StepKind
+		ex   plotter.XYs
+	}{
+		{plotter.NoStep, xys},
+		{plotter.PreStep, plotter.XYs{{X: 0, Y: 0}, {X: 0, Y: 2}, {X: 1, Y: 2}, {X: 1, Y: 1}, {X: 3, Y: 1}}},
+		{plotter.PostStep, plotter.XYs{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 2}, {X: 3, Y: 2}, {X: 3, Y: 1}}},
+		{plotter.MidStep, plotter.XYs{{X: 0, Y: 0}, {X: 0.5, Y: 0}, {X: 0.5, Y: 2}, {X: 1, Y: 2}, {X: 2, Y: 2}, {X: 2, Y: 1}, {X: 3, Y: 1}}},
+	}
+
+	for _, row := range table {
+		if got := stepXYs(xys, row.kind); !slices.Equal(got, row.ex) {
+			t.Errorf("step kind %d: got %v, expected %v", row.kind, got, row.ex)
+		}
+	}
+}
+
+func TestEnvelopeLineStepStyle(t *testing.T) {
+	const buckets = 10
+
+	el, err := NewEnvelopeLine(sineBuffer(10000, 1000, 1, 1), buckets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	el.Line.StepStyle = plotter.PostStep
+
+	p := plot.New()
+	p.Add(el)
+
+	rec := new(recorder.Canvas)
+	p.Draw(draw.NewCanvas(rec, 4*vg.Inch, 3*vg.Inch))
+
+	polys := filledPaths(rec, el.fillColor())
+	if len(polys) != 1 {
+		t.Fatalf("got %d filled polygons, expected 1", len(polys))
+	}
+	// each edge gains a corner vertex
+	if n, ex := pathVertices(polys[0]), 2*(2*buckets-1); n != ex {
+		t.Errorf("got %d polygon vertices, expected %d", n, ex)
+	}
+}
+
+func TestLoadSampleBufferGzip(t *testing.T) {
+	s := sineBuffer(500, 1000, 7, 2)
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := s.WriteTo(zw); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "samples.bin.gz")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadSampleBufferGzip(path, s.Len(), s.SampleRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(loaded.Samples, s.Samples) {
+		t.Error("loaded samples differ from compressed samples")
+	}
+
+	// not gzipped
+	path = filepath.Join(dir, "samples.bin")
+	if err := SaveSampleBuffer(path, s); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSampleBufferGzip(path, s.Len(), s.SampleRate); err == nil || !strings.Contains(err.Error(), "decompressing") {
+		t.Errorf("got error %v, expected a decompression error", err)
+	}
+}
+
+func TestNewAxisTicker(t *testing.T) {
+	c := draw.NewCanvas(new(recorder.Canvas), 6*vg.Inch, 2*vg.Inch)
+
+	if got := NewAxisTicker(c, true).Dim; got != 6*vg.Inch {
+		t.Errorf("horizontal Dim = %v, expected %v", got, 6*vg.Inch)
+	}
+	if got := NewAxisTicker(c, false).Dim; got != 2*vg.Inch {
+		t.Errorf("vertical Dim = %v, expected %v", got, 2*vg.Inch)
+	}
+
+	// a shorter axis gets fewer ticks than the 800 default
+	short := NewAxisTicker(c, false).Ticks(0, 100)
+	def := AutoTicker{}.Ticks(0, 100)
+	if len(short) >= len(def) {
+		t.Errorf("got %d ticks on a 2in axis, expected fewer than the default %d", len(short), len(def))
+	}
+}
+
+func TestTickerLabelAll(t *testing.T) {
+	dut := AutoTicker{LabelAll: true, SigFigs: 2, Unit: "V"}
+	ticks := dut.Ticks(0, 1)
+	if len(ticks) < 2 {
+		t.Fatalf("got %d ticks", len(ticks))
+	}
+	for _, tick := range ticks {
+		if tick.Label == "" {
+			t.Errorf("tick at %v has no label", tick.Value)
+		}
+	}
+	if got, ex := ticks[1].Label, "0.01 V"; got != ex {
+		t.Errorf("got label %q, expected %q", got, ex)
+	}
+}
+
+func TestQuantizedLineBuildPolygon(t *testing.T) {
+	s := sineBuffer(10000, 1000, 3, 1)
+	ql, err := NewQuantizedLine(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const width = 100
+	poly, err := ql.BuildPolygon(draw.NewCanvas(new(recorder.Canvas), width, width))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if poly == nil {
+		t.Fatal("got no polygon")
+	}
+	if len(poly.XYs) != 1 || len(poly.XYs[0]) != 2*width {
+		t.Fatalf("got polygon rings %v, expected one ring of %d vertices", len(poly.XYs), 2*width)
+	}
+
+	// the upper edge runs left to right and the lower edge back again, so each
+	// vertex faces its counterpart at the same X
+	ring := poly.XYs[0]
+	for i := 0; i < width; i++ {
+		up, low := ring[i], ring[len(ring)-1-i]
+		if up.X != low.X {
+			t.Errorf("vertex %d at x=%v faces x=%v", i, up.X, low.X)
+		}
+		if up.Y < low.Y {
+			t.Errorf("vertex %d: upper %v is below lower %v", i, up.Y, low.Y)
+		}
+		if i > 0 && up.X <= ring[i-1].X {
+			t.Errorf("upper edge is not increasing at vertex %d", i)
+		}
+	}
+
+	// few enough points to be drawn as-is
+	poly, err = ql.BuildPolygon(draw.NewCanvas(new(recorder.Canvas), 10000, 100))
+	if err != nil || poly != nil {
+		t.Errorf("got %v, %v, expected no polygon", poly, err)
+	}
+}
+
+func TestSampleBufferIndexMode(t *testing.T) {
+	s := &SampleBuffer{
+		Samples:    make([]float64, 100),
+		SampleRate: 1000,
+		StartIndex: 50,
+		TimeOffset: 2,
+	}
+
+	if x, _ := s.XY(0); x != 2.05 {
+		t.Errorf("time mode: got first x %v, expected 2.05", x)
+	}
+
+	s.IndexMode = true
+	if x, _ := s.XY(0); x != 50 {
+		t.Errorf("index mode: got first x %v, expected 50", x)
+	}
+	if x, _ := s.XY(99); x != 149 {
+		t.Errorf("index mode: got last x %v, expected 149", x)
+	}
+	if xmin, xmax, _, _ := s.DataRange(); xmin != 50 || xmax != 149 {
+		t.Errorf("index mode: got x range [%v, %v], expected [50, 149]", xmin, xmax)
+	}
+
+	s.IndexMode = false
+	if x, _ := s.XY(99); x != 2.149 {
+		t.Errorf("time mode: got last x %v, expected 2.149", x)
+	}
+}
+
+func TestQuantizedLineCache(t *testing.T) {
+	ql, err := NewQuantizedLine(sineBuffer(10000, 1000, 3, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := ql.envelope(100, 0, 10)
+	if again := ql.envelope(100, 0, 10); &again.mins[0] != &e.mins[0] {
+		t.Error("envelope was recomputed for an unchanged line")
+	}
+	if other := ql.envelope(50, 0, 10); &other.mins[0] == &e.mins[0] {
+		t.Error("envelope was reused for a different bucket count")
+	}
+
+	// replaced data
+	ql.Line.XYs = ql.Line.XYs[:5000]
+	e = ql.envelope(100, 0, 10)
+	if e.maxes[len(e.maxes)-1].X > 5 {
+		t.Errorf("stale envelope reaches x=%v after truncating the data", e.maxes[len(e.maxes)-1].X)
+	}
+
+	// modified in place
+	ql.Line.XYs[0].Y = 100
+	ql.InvalidateCache()
+	if e = ql.envelope(100, 0, 10); e.maxes[0].Y != 100 {
+		t.Errorf("got max %v after invalidating, expected 100", e.maxes[0].Y)
+	}
+}
+
+func TestEnvelopeLineCacheSharing(t *testing.T) {
+	el, err := NewEnvelopeLine(sineBuffer(10000, 1000, 3, 1), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a plot range wider than the data, as after padding the axes
+	p := plot.New()
+	p.X.Min, p.X.Max = -1, 11
+	p.Y.Min, p.Y.Max = -1, 1
+	c := draw.NewCanvas(new(recorder.Canvas), 100, 100)
+
+	el.Plot(c, p)
+	if _, err := el.BuildPolygon(c); err != nil {
+		t.Fatal(err)
+	}
+	plotted, built := el.cache.e, el.polygon.e
+
+	el.Plot(c, p)
+	if _, err := el.BuildPolygon(c); err != nil {
+		t.Fatal(err)
+	}
+	if &el.cache.e.mins[0] != &plotted.mins[0] {
+		t.Error("Plot recomputed the envelope after BuildPolygon")
+	}
+	if &el.polygon.e.mins[0] != &built.mins[0] {
+		t.Error("BuildPolygon recomputed the envelope after Plot")
+	}
+}
+
+func benchmarkQuantizedLinePlot(b *testing.B, invalidate bool) {
+	s := &SampleBuffer{Samples: make([]float64, 10_000_000), SampleRate: 1e6}
+	for i := range s.Samples {
+		s.Samples[i] = math.Sin(float64(i) / 1000)
+	}
+	ql, err := NewQuantizedLine(s)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	p := plot.New()
+	p.X.Min, p.X.Max, p.Y.Min, p.Y.Max = ql.DataRange()
+	c := draw.NewCanvas(new(recorder.Canvas), 10*vg.Inch, 4*vg.Inch)
+	ql.Plot(c, p)
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		if invalidate {
+			ql.InvalidateCache()
+		}
+		ql.Plot(c, p)
+	}
+}
+
+func BenchmarkQuantizedLinePlotUncached(b *testing.B) {
+	benchmarkQuantizedLinePlot(b, true)
+}
+
+func BenchmarkQuantizedLinePlotCached(b *testing.B) {
+	benchmarkQuantizedLinePlot(b, false)
+}
+
+func TestTickerSIDigits(t *testing.T) {
+	table := []struct {
+		digits int
+		label  string
+	}{
+		{0, "1.2345 kHz"},
+		{1, "1.2 kHz"},
+		{2, "1.23 kHz"},
+		{6, "1.2345 kHz"},
+	}
+
+	for _, row := range table {
+		dut := AutoTicker{SIDigits: row.digits, SigFigs: 5, Unit: "Hz"}
+		if got := dut.formatLabel(1234.5, 5, 3); got != row.label {
+			t.Errorf("%d digits: got %q, expected %q", row.digits, got, row.label)
+		}
+	}
+
+	// plain labels aren't affected
+	if got := (AutoTicker{SIDigits: 1}).formatLabel(12.345, 5, 0); got != "12.345" {
+		t.Errorf("got plain label %q, expected 12.345", got)
+	}
+}
+
+func TestTickerUniformSIPrefix(t *testing.T) {
+	table := []struct {
+		min, max float64
+		prefix   string
+	}{
+		{0, 1000, "k"},
+		{0, 2500, "k"},
+		{-0.002, 0.0015, "m"},
+	}
+
+	for _, row := range table {
+		for _, tick := range (AutoTicker{Unit: "V"}).Ticks(row.min, row.max) {
+			if tick.Label == "" || tick.Value == 0 {
+				continue
+			}
+			if !strings.HasSuffix(tick.Label, " "+row.prefix+"V") {
+				t.Errorf("[%g, %g]: label %q at %g doesn't have prefix %q", row.min, row.max, tick.Label, tick.Value, row.prefix)
+			}
+		}
+	}
+
+	labels := []string{}
+	for _, tick := range (AutoTicker{}).Ticks(0, 1000) {
+		if tick.Label != "" {
+			labels = append(labels, tick.Label)
+		}
+	}
+	if labels[0] != "0" || labels[5] != "0.5 k" || labels[10] != "1 k" {
+		t.Errorf("got labels %q", labels)
+	}
+}
+
+func TestMapXYer(t *testing.T) {
+	s := &SampleBuffer{Samples: []float64{1, -2, 3}, SampleRate: 10}
+
+	calls := 0
+	m := MapXYer(s, func(y float64) float64 {
+		calls++
+		return 2 * y
+	})
+	if calls != 0 {
+		t.Errorf("f was called %d times before reading", calls)
+	}
+	if m.Len() != s.Len() {
+		t.Fatalf("got %d points, expected %d", m.Len(), s.Len())
+	}
+	for i := 0; i < m.Len(); i++ {
+		x, y := m.XY(i)
+		sx, sy := s.XY(i)
+		if x != sx || y != 2*sy {
+			t.Errorf("point %d: got (%v, %v), expected (%v, %v)", i, x, y, sx, 2*sy)
+		}
+	}
+
+	// later changes to the underlying data show through
+	s.Samples[0] = 5
+	if _, y := m.XY(0); y != 10 {
+		t.Errorf("got %v after changing the sample, expected 10", y)
+	}
+}
+
+func TestTickerAnchor(t *testing.T) {
+	hasMajor := func(ticks []plot.Tick, v float64) bool {
+		return slices.ContainsFunc(ticks, func(tick plot.Tick) bool {
+			return tick.Value == v && tick.Label != ""
+		})
+	}
+
+	if ticks := (AutoTicker{}).Ticks(0, 1); hasMajor(ticks, 0.05) {
+		t.Fatal("unanchored ticks already have a major at 0.05")
+	}
+
+	ticks := AutoTicker{Anchor: 0.05}.Ticks(0, 1)
+	if !hasMajor(ticks, 0.05) {
+		t.Errorf("got no major at the anchor: %v", ticks)
+	}
+	if !hasMajor(ticks, 0.15) {
+		t.Errorf("got no major one interval past the anchor: %v", ticks)
+	}
+	if len(ticks) != len(AutoTicker{}.Ticks(0, 1)) {
+		t.Errorf("got %d ticks, expected the same spacing as unanchored", len(ticks))
+	}
+
+	// an anchor far outside the range still sets the grid
+	ticks = AutoTicker{Anchor: 100.25}.Ticks(0, 1)
+	if !hasMajor(ticks, 0.25) {
+		t.Errorf("got no major at 0.25 with anchor 100.25: %v", ticks)
+	}
+}
+
+func TestQuantizedLineNonMonotonic(t *testing.T) {
+	// Lissajous figure
+	xys := make(plotter.XYs, 10000)
+	for i := range xys {
+		a := 2 * math.Pi * float64(i) / float64(len(xys))
+		xys[i] = plotter.XY{X: math.Sin(3 * a), Y: math.Sin(2 * a)}
+	}
+	ql, err := NewQuantizedLine(xys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const width = 100
+	if ql.ShouldAggregate(width) {
+		t.Error("ShouldAggregate is true for non-monotonic X")
+	}
+
+	p := plot.New()
+	p.X.Min, p.X.Max, p.Y.Min, p.Y.Max = -1, 1, -1, 1
+	rec := new(recorder.Canvas)
+	ql.Plot(draw.NewCanvas(rec, width, width), p)
+	if n := len(filledPaths(rec, ql.fillColor())); n != 0 {
+		t.Errorf("got %d envelope fills, expected the raw line", n)
+	}
+
+	el := &EnvelopeLine{QuantizedLine: *ql, Buckets: 10}
+	if poly, err := el.BuildPolygon(draw.Canvas{}); err != nil || poly != nil {
+		t.Errorf("EnvelopeLine: got polygon %v, %v, expected none", poly, err)
+	}
+
+	// sorting the same points by X makes them aggregate
+	ql.Line.XYs = slices.Clone(xys)
+	slices.SortFunc(ql.Line.XYs, func(a, b plotter.XY) int { return cmp.Compare(a.X, b.X) })
+	if !ql.ShouldAggregate(width) {
+		t.Error("ShouldAggregate is false after sorting by X")
+	}
+}
+
+func TestNiceRange(t *testing.T) {
+	table := []struct {
+		min, max float64
+		dim      vg.Length
+		lo, hi   float64
+	}{
+		{0.3, 9.7, 0, 0, 10},
+		{0.3, 9.7, 105, 0, 10},
+		{0, 10, 0, 0, 10},
+		{-13.3, -4.1, 1294, -13.5, -4},
+		{0.012, 0.087, 0, 0.01, 0.09},
+		// too short an axis for two labeled majors inside the range
+		{13, 87, 100, 0, 100},
+		{-0.37, 0.82, 100, -1, 1},
+	}
+
+	for _, row := range table {
+		lo, hi := NiceRange(row.min, row.max, row.dim)
+		if lo != row.lo || hi != row.hi {
+			t.Errorf("[%v, %v] at dim %v: got [%v, %v], expected [%v, %v]", row.min, row.max, row.dim, lo, hi, row.lo, row.hi)
+		}
+	}
+}
+
+func TestQuantizedLineBuckets(t *testing.T) {
+	ql, err := NewQuantizedLine(sineBuffer(10000, 1000, 3, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ql.Buckets = 100
+
+	for _, width := range []vg.Length{50, 300, 4000} {
+		poly, err := ql.BuildPolygon(draw.NewCanvas(new(recorder.Canvas), width, 100))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if poly == nil {
+			t.Errorf("width %v: got no polygon", width)
+			continue
+		}
+		if n := len(poly.XYs[0]); n != 200 {
+			t.Errorf("width %v: got %d vertices, expected 200", width, n)
+		}
+	}
+
+	// the threshold follows the bucket count rather than the width
+	ql.Buckets = 6000
+	if ql.ShouldAggregate(50) {
+		t.Error("aggregating 10000 points into 6000 buckets")
+	}
+}
+
+func TestQuantizedLineGap(t *testing.T) {
+	s := sineBuffer(10000, 1000, 3, 1)
+	for i := 4000; i < 6000; i++ {
+		s.Samples[i] = math.NaN()
+	}
+	ql, err := NewQuantizedLine(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	poly, err := ql.BuildPolygon(draw.NewCanvas(new(recorder.Canvas), 100, 100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if poly == nil || len(poly.XYs) != 2 {
+		t.Fatalf("got %v, expected two rings", poly)
+	}
+
+	left, right := poly.XYs[0], poly.XYs[1]
+	for _, p := range left {
+		if p.X >= 4 {
+			t.Fatalf("left ring reaches into the gap at x=%v", p.X)
+		}
+	}
+	for _, p := range right {
+		if p.X < 6 {
+			t.Fatalf("right ring reaches into the gap at x=%v", p.X)
+		}
+	}
+	if len(left)+len(right) != 2*80 {
+		t.Errorf("got %d vertices, expected %d for 80 non-empty buckets", len(left)+len(right), 2*80)
+	}
+}
+
+// referenceAggregate is a straightforward two-pass version of the min/max part
+// of aggregate that collects the Y values of each bucket before reducing them,
+// for checking the single-pass implementation against.
+func referenceAggregate(xyer plotter.XYer, n int, xmin, xmax float64) (mins, maxes plotter.XYs) {
+	xs := make([][]float64, n)
+	ys := make([][]float64, n)
+	width := (xmax - xmin) / float64(n)
+	for i := 0; i < xyer.Len(); i++ {
+		x, y := xyer.XY(i)
+		if !isFinite(x) || !isFinite(y) || x < xmin || x > xmax {
+			continue
+		}
+		j := 0
+		if width > 0 {
+			j = min(int(math.Floor((x-xmin)/width)), n-1)
+		}
+		xs[j] = append(xs[j], x)
+		ys[j] = append(ys[j], y)
+	}
+
+	for j := range ys {
+		if len(ys[j]) == 0 {
+			continue
+		}
+		x := slices.Min(xs[j])
+		mins = append(mins, plotter.XY{X: x, Y: slices.Min(ys[j])})
+		maxes = append(maxes, plotter.XY{X: x, Y: slices.Max(ys[j])})
+	}
+	return mins, maxes
+}
+
+func TestAggregateNarrowedRange(t *testing.T) {
+	// a spike well off screen to the left of the viewed range
+	s := sineBuffer(100000, 1000, 3, 1)
+	s.Samples[1000] = 50
+
+	e := aggregate(s, 100, 40, 60)
+	if len(e.mins) != 100 {
+		t.Fatalf("got %d buckets, expected 100", len(e.mins))
+	}
+	for i := range e.maxes {
+		if x := e.maxes[i].X; x < 40 || x > 60 {
+			t.Errorf("bucket %d at X %v, outside of [40, 60]", i, x)
+		}
+		if y := e.maxes[i].Y; y > 1 {
+			t.Errorf("bucket %d has max %v from an off-screen point", i, y)
+		}
+	}
+
+	// and drawn on an axis zoomed in the same way, the envelope doesn't
+	// reach up to the spike
+	ql, err := NewQuantizedLine(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := plot.New()
+	p.X.Min, p.X.Max, p.Y.Min, p.Y.Max = 40, 60, -2, 2
+	rec := new(recorder.Canvas)
+	c := draw.NewCanvas(rec, 100, 100)
+	ql.Plot(c, p)
+
+	polys := filledPaths(rec, ql.fillColor())
+	if len(polys) == 0 {
+		t.Fatal("expected envelope to be drawn")
+	}
+	for _, comp := range polys[0] {
+		if comp.Type != vg.CloseComp && comp.Pos.Y >= c.Max.Y {
+			t.Errorf("envelope vertex %v reaches the top of the canvas", comp.Pos)
+		}
+	}
+}
+
+func TestAggregateMatchesReference(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	noise := make(plotter.XYs, 5000)
+	for i := range noise {
+		noise[i] = plotter.XY{X: rng.Float64() * 10, Y: rng.NormFloat64()}
+	}
+	gappy := make(plotter.XYs, 3000)
+	for i := range gappy {
+		gappy[i] = plotter.XY{X: float64(i), Y: math.Sin(float64(i) / 50)}
+		if i%7 == 0 || (i > 1000 && i < 1500) {
+			gappy[i].Y = math.NaN()
+		}
+	}
+
+	table := []struct {
+		name       string
+		xyer       plotter.XYer
+		n          int
+		xmin, xmax float64
+	}{
+		{"sine", sineBuffer(10000, 1000, 3, 1), 100, 0, 10},
+		{"sine narrowed", sineBuffer(10000, 1000, 3, 1), 37, 2.5, 7.25},
+		{"noise", noise, 250, 0, 10},
+		{"gappy", gappy, 64, 0, 3000},
+		{"flat x", plotter.XYs{{X: 1, Y: 3}, {X: 1, Y: -2}, {X: 1, Y: 5}}, 10, 1, 1},
+	}
+
+	for _, row := range table {
+		e := aggregate(row.xyer, row.n, row.xmin, row.xmax)
+		mins, maxes := referenceAggregate(row.xyer, row.n, row.xmin, row.xmax)
+		if !slices.Equal(e.mins, mins) {
+			t.Errorf("%s: mins differ from the reference", row.name)
+		}
+		if !slices.Equal(e.maxes, maxes) {
+			t.Errorf("%s: maxes differ from the reference", row.name)
+		}
+	}
+}
+
+func benchmarkAggregateLarge() *SampleBuffer {
+	s := &SampleBuffer{Samples: make([]float64, 10_000_000), SampleRate: 1e6}
+	for i := range s.Samples {
+		s.Samples[i] = math.Sin(float64(i) / 1000)
+	}
+	return s
+}
+
+func BenchmarkAggregate(b *testing.B) {
+	s := benchmarkAggregateLarge()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		aggregate(s, 1000, 0, 10)
+	}
+}
+
+func BenchmarkAggregateReference(b *testing.B) {
+	s := benchmarkAggregateLarge()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		referenceAggregate(s, 1000, 0, 10)
+	}
+}
+
+func TestTickerLabelStyle(t *testing.T) {
+	table := []struct {
+		style  LabelStyle
+		labels [4]string // 1500, 15000, 0.0025, 42
+	}{
+		{LabelSI, [4]string{"1.5 kV", "15 kV", "2.5 mV", "42 V"}},
+		{LabelEngineering, [4]string{"1.5e3 V", "15e3 V", "2.5e-3 V", "42 V"}},
+		{LabelScientific, [4]string{"1.5e3 V", "1.5e4 V", "2.5e-3 V", "4.2e1 V"}},
+		{LabelPlain, [4]string{"1500 V", "15000 V", "0.0025 V", "42 V"}},
+	}
+
+	for _, row := range table {
+		dut := AutoTicker{LabelStyle: row.style, Unit: "V"}
+		for i, v := range []float64{1500, 15000, 0.0025, 42} {
+			if got := dut.formatLabel(v, 3, siExponent(v)); got != row.labels[i] {
+				t.Errorf("style %d, %v: got %q, expected %q", row.style, v, got, row.labels[i])
+			}
+		}
+	}
+}
+
+func TestTickerParams(t *testing.T) {
+	table := []struct {
+		dut      AutoTicker
+		min, max float64
+	}{
+		{AutoTicker{}, 0, 1},
+		{AutoTicker{Dim: 105}, 0.5, 10},
+		{AutoTicker{Dim: 1294}, -13.3, -4.1},
+		{AutoTicker{NiceSteps: true}, 0, 0.9},
+		{AutoTicker{MaxLabels: 3}, 0, 1},
+		{AutoTicker{}, 5, 5},
+	}
+
+	for _, row := range table {
+		spacing, interval := row.dut.Params(row.min, row.max)
+		ticks := row.dut.Ticks(row.min, row.max)
+
+		if got := ticks[1].Value - ticks[0].Value; math.Abs(got-spacing) > spacing*1e-9 {
+			t.Errorf("[%v, %v]: Params spacing %v, Ticks spacing %v", row.min, row.max, spacing, got)
+		}
+
+		var labeled []int
+		for i, tick := range ticks {
+			if tick.Label != "" {
+				labeled = append(labeled, i)
+			}
+		}
+		if len(labeled) >= 2 {
+			if got := labeled[1] - labeled[0]; got != interval {
+				t.Errorf("[%v, %v]: Params interval %d, Ticks interval %d", row.min, row.max, interval, got)
+			}
+		}
+	}
+}
+
+func TestQuantizedLineSolidEnvelope(t *testing.T) {
+	// dash patterns in effect for each stroke
+	strokeDashes := func(ql *QuantizedLine) [][]vg.Length {
+		p := plot.New()
+		p.X.Min, p.X.Max, p.Y.Min, p.Y.Max = 0, 10, -1, 1
+		rec := new(recorder.Canvas)
+		ql.Plot(draw.NewCanvas(rec, 100, 100), p)
+
+		var (
+			ret [][]vg.Length
+			cur []vg.Length
+		)
+		for _, a := range rec.Actions {
+			switch a := a.(type) {
+			case *recorder.SetLineDash:
+				cur = a.Dashes
+			case *recorder.Stroke:
+				ret = append(ret, cur)
+			}
+		}
+		return ret
+	}
+
+	ql, err := NewQuantizedLine(sineBuffer(10000, 1000, 3, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ql.LineStyle.Dashes = []vg.Length{2, 2}
+	ql.DrawMean = true
+
+	for _, solid := range []bool{false, true} {
+		ql.SolidEnvelope = solid
+		// the transparent polygon outline comes first
+		dashes := strokeDashes(ql)
+		if len(dashes) != 4 {
+			t.Fatalf("got %d strokes, expected outline, upper, lower and mean", len(dashes))
+		}
+		dashes = dashes[1:]
+		for i, d := range dashes[:2] {
+			if dashed := len(d) > 0; dashed == solid {
+				t.Errorf("SolidEnvelope=%t: bound %d has dashes %v", solid, i, d)
+			}
+		}
+		if len(dashes[2]) == 0 {
+			t.Errorf("SolidEnvelope=%t: mean line lost its dashes", solid)
+		}
+	}
+	if len(ql.LineStyle.Dashes) != 2 {
+		t.Error("drawing changed the line style")
+	}
+}
+
+func TestSampleBufferFromXYer(t *testing.T) {
+	src := &SampleBuffer{Samples: []float64{3, 1, 4, 1, 5}, SampleRate: 250, StartIndex: 10, TimeOffset: 1}
+	var xys plotter.XYs
+	for i := 0; i < src.Len(); i++ {
+		x, y := src.XY(i)
+		xys = append(xys, plotter.XY{X: x, Y: y})
+	}
+
+	s, err := SampleBufferFromXYer(xys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(s.SampleRate-250) > 1e-9 {
+		t.Errorf("got sample rate %v, expected 250", s.SampleRate)
+	}
+	if !slices.Equal(s.Samples, src.Samples) {
+		t.Errorf("got samples %v, expected %v", s.Samples, src.Samples)
+	}
+	for i := range xys {
+		if x, _ := s.XY(i); math.Abs(x-xys[i].X) > 1e-12 {
+			t.Errorf("point %d: got x %v, expected %v", i, x, xys[i].X)
+		}
+	}
+
+	xys[2].X += 0.001
+	if _, err := SampleBufferFromXYer(xys); err == nil {
+		t.Error("expected an error for non-uniform spacing")
+	}
+	if _, err := SampleBufferFromXYer(xys[:1]); err == nil {
+		t.Error("expected an error for a single point")
+	}
+	if _, err := SampleBufferFromXYer(plotter.XYs{{X: 1}, {X: 0}}); err == nil {
+		t.Error("expected an error for decreasing X")
+	}
+}
+
+func TestTickerPitch(t *testing.T) {
+	labels := func(dut AutoTicker) (n, total int) {
+		ticks := dut.Ticks(0, 100)
+		for _, tick := range ticks {
+			if tick.Label != "" {
+				n++
+			}
+		}
+		return n, len(ticks)
+	}
+
+	defLabels, defTicks := labels(AutoTicker{})
+	if n, _ := labels(AutoTicker{LabelPitch: vg.Inch / 2}); n <= defLabels {
+		t.Errorf("got %d labels at half the label pitch, expected more than %d", n, defLabels)
+	}
+	if _, n := labels(AutoTicker{TickPitch: vg.Inch / 50}); n <= defTicks {
+		t.Errorf("got %d ticks at a tenth of the tick pitch, expected more than %d", n, defTicks)
+	}
+	if n, total := labels(AutoTicker{TickPitch: vg.Inch / 5, LabelPitch: vg.Inch}); n != defLabels || total != defTicks {
+		t.Errorf("got %d labels and %d ticks at the default pitches, expected %d and %d", n, total, defLabels, defTicks)
+	}
+}
+
+func TestQuantizedLineOversample(t *testing.T) {
+	ql, err := NewQuantizedLine(sineBuffer(100000, 1000, 3, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const width = 100
+	for _, oversample := range []int{0, 1, 2, 4} {
+		ql.Oversample = oversample
+		poly, err := ql.BuildPolygon(draw.NewCanvas(new(recorder.Canvas), width, width))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if poly == nil {
+			t.Fatalf("oversample %d: got no polygon", oversample)
+		}
+		if n, ex := len(poly.XYs[0]), 2*width*max(1, oversample); n != ex {
+			t.Errorf("oversample %d: got %d vertices, expected %d", oversample, n, ex)
+		}
+	}
+}
+
+func TestTickerPercent(t *testing.T) {
+	labels := func(ticks []plot.Tick) []string {
+		var ret []string
+		for _, tick := range ticks {
+			if tick.Label != "" {
+				ret = append(ret, tick.Label)
+			}
+		}
+		return ret
+	}
+
+	dut := AutoTicker{Percent: true}
+	got := labels(dut.Ticks(0, 1))
+	ex := []string{"0%", "10%", "20%", "30%", "40%", "50%", "60%", "70%", "80%", "90%", "100%"}
+	if !slices.Equal(got, ex) {
+		t.Errorf("[0, 1]: got %v, expected %v", got, ex)
+	}
+
+	// the spacing is the same as without Percent
+	if a, b := dut.Ticks(0, 1), (AutoTicker{}).Ticks(0, 1); len(a) != len(b) {
+		t.Errorf("got %d ticks, expected %d", len(a), len(b))
+	}
+
+	got = labels(dut.Ticks(-0.5, 2.5))
+	if got[0] != "-50%" || got[len(got)-1] != "250%" {
+		t.Errorf("[-0.5, 2.5]: got %v", got)
+	}
+}
+
+func TestQuantizedLineGradientFill(t *testing.T) {
+	ql, err := NewQuantizedLine(sineBuffer(10000, 1000, 3, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ql.GradientFill = true
+
+	p := plot.New()
+	p.X.Min, p.X.Max, p.Y.Min, p.Y.Max = 0, 10, -1, 1
+	rec := new(recorder.Canvas)
+	ql.Plot(draw.NewCanvas(rec, 100, 100), p)
+
+	var alphas []uint16
+	var cur color.Color
+	for _, a := range rec.Actions {
+		switch a := a.(type) {
+		case *recorder.SetColor:
+			cur = a.Color
+		case *recorder.Fill:
+			_, _, _, a16 := cur.RGBA()
+			alphas = append(alphas, uint16(a16))
+		}
+	}
+	if len(alphas) != 2*gradientBands {
+		t.Fatalf("got %d fills, expected %d bands", len(alphas), 2*gradientBands)
+	}
+
+	_, _, _, inner := ql.fillColor().RGBA()
+	for i := 0; i < len(alphas); i += 2 {
+		if alphas[i] != alphas[i+1] {
+			t.Errorf("band %d: upper alpha %d, lower alpha %d", i/2, alphas[i], alphas[i+1])
+		}
+		if i > 0 && alphas[i] >= alphas[i-2] {
+			t.Errorf("band %d: alpha %d doesn't fade from %d", i/2, alphas[i], alphas[i-2])
+		}
+	}
+	if alphas[0] >= uint16(inner) || alphas[len(alphas)-1] == 0 {
+		t.Errorf("got alphas %v within fill alpha %d", alphas, inner)
+	}
+
+	// and the default raster canvas takes it too
+	p.Add(ql)
+	w, err := p.WriterTo(100, 100, "png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.WriteTo(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTickerTrimEdges(t *testing.T) {
+	all := AutoTicker{}.Ticks(0, 1)
+	trimmed := AutoTicker{TrimEdges: true}.Ticks(0, 1)
+	if len(trimmed) != len(all)-2 {
+		t.Fatalf("got %d ticks, expected %d without the ends", len(trimmed), len(all)-2)
+	}
+	for _, tick := range trimmed {
+		if tick.Value == 0 || tick.Value == 1 {
+			t.Errorf("got tick %+v on the edge", tick)
+		}
+	}
+	if !slices.Equal(trimmed, all[1:len(all)-1]) {
+		t.Errorf("interior ticks changed: got %v, expected %v", trimmed, all[1:len(all)-1])
+	}
+
+	// ends off the tick grid lose nothing, and forced majors stay
+	if got := (AutoTicker{TrimEdges: true}).Ticks(-0.035, 1.075); !slices.Equal(got, AutoTicker{}.Ticks(-0.035, 1.075)) {
+		t.Errorf("ticks over an unaligned range changed: %v", got)
+	}
+	ticks := AutoTicker{TrimEdges: true, ForcedMajors: []float64{1}}.Ticks(0, 1)
+	if last := ticks[len(ticks)-1]; last.Value != 1 || last.Label == "" {
+		t.Errorf("got last tick %+v, expected the forced major at 1", last)
+	}
+}
@@ -0,0 +1,157 @@
+package plotext
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotutil"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// Option configures a plot built by QuickPlot.
+type Option func(*quickPlotConfig)
+
+type quickPlotConfig struct {
+	title          string
+	xLabel, yLabel string
+	xDim, yDim     vg.Length
+}
+
+// WithTitle sets the title of the plot.
+func WithTitle(title string) Option {
+	return func(c *quickPlotConfig) {
+		c.title = title
+	}
+}
+
+// WithAxisLabels sets the labels of the X and Y axes.
+func WithAxisLabels(x, y string) Option {
+	return func(c *quickPlotConfig) {
+		c.xLabel = x
+		c.yLabel = y
+	}
+}
+
+// WithDim sets the lengths of the X and Y axes used by their AutoTickers to
+// choose the tick density. It should match the size the plot is rendered at.
+func WithDim(x, y vg.Length) Option {
+	return func(c *quickPlotConfig) {
+		c.xDim = x
+		c.yDim = y
+	}
+}
+
+// QuickPlot builds a plot with AutoTickers on both axes and a QuantizedLine
+// for each buffer, each in a distinct color.
+func QuickPlot(buffers []*SampleBuffer, opts ...Option) (*plot.Plot, error) {
+	var cfg quickPlotConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	p := plot.New()
+	p.Title.Text = cfg.title
+	p.X.Label.Text = cfg.xLabel
+	p.Y.Label.Text = cfg.yLabel
+	p.X.Tick.Marker = AutoTicker{Dim: cfg.xDim}
+	p.Y.Tick.Marker = AutoTicker{Dim: cfg.yDim}
+
+	for i, buf := range buffers {
+		ql, err := NewQuantizedLine(buf)
+		if err != nil {
+			return nil, err
+		}
+		ql.Line.Color = plotutil.Color(i)
+		p.Add(ql)
+	}
+
+	return p, nil
+}
+
+// Grid is a set of plots laid out in rows and columns on a single canvas, with
+// their data areas aligned.
+type Grid struct {
+	// Plots holds the plots by row and then column. Cells may be nil.
+	Plots [][]*plot.Plot
+
+	// Tiles sets the number of rows and columns and the padding around them.
+	Tiles draw.Tiles
+}
+
+// GridPlot builds a Grid of QuickPlots, one per buffer, filling rows left to
+// right from the top, for drawing at the given overall size. All plots share
+// the same X range and AutoTicker, so their time axes line up, and the tickers
+// are sized to the tiles rather than the whole grid. There must be at most
+// rows*cols buffers.
+func GridPlot(buffers []*SampleBuffer, rows, cols int, w, h vg.Length) (*Grid, error) {
+	if rows <= 0 || cols <= 0 {
+		return nil, fmt.Errorf("plotext: invalid grid size %dx%d", rows, cols)
+	}
+	if len(buffers) > rows*cols {
+		return nil, fmt.Errorf("plotext: %d buffers don't fit in a %dx%d grid", len(buffers), rows, cols)
+	}
+
+	xmin, xmax := math.Inf(1), math.Inf(-1)
+	for _, buf := range buffers {
+		bxmin, bxmax, _, _ := buf.DataRange()
+		xmin, xmax = min(xmin, bxmin), max(xmax, bxmax)
+	}
+
+	g := &Grid{
+		Plots: make([][]*plot.Plot, rows),
+		Tiles: draw.Tiles{
+			Rows: rows,
+			Cols: cols,
+			PadX: vg.Millimeter,
+			PadY: vg.Millimeter,
+		},
+	}
+	for j := range g.Plots {
+		g.Plots[j] = make([]*plot.Plot, cols)
+	}
+
+	tiles := g.Tiles
+	tileW := (w - tiles.PadLeft - tiles.PadRight - vg.Length(cols-1)*tiles.PadX) / vg.Length(cols)
+	tileH := (h - tiles.PadTop - tiles.PadBottom - vg.Length(rows-1)*tiles.PadY) / vg.Length(rows)
+	xTicker := AutoTicker{Dim: tileW}
+
+	for i, buf := range buffers {
+		p, err := QuickPlot([]*SampleBuffer{buf}, WithDim(tileW, tileH))
+		if err != nil {
+			return nil, err
+		}
+		p.X.Tick.Marker = xTicker
+		if xmin <= xmax {
+			p.X.Min, p.X.Max = xmin, xmax
+		}
+		g.Plots[i/cols][i%cols] = p
+	}
+
+	return g, nil
+}
+
+// Draw draws the plots to c, aligned in their tiles.
+func (g *Grid) Draw(c draw.Canvas) {
+	canvases := plot.Align(g.Plots, g.Tiles, c)
+	for j, row := range g.Plots {
+		for i, p := range row {
+			if p != nil {
+				p.Draw(canvases[j][i])
+			}
+		}
+	}
+}
+
+// WriterTo returns an io.WriterTo that writes the grid at the given size in
+// the given format, like plot.Plot.WriterTo.
+func (g *Grid) WriterTo(w, h vg.Length, format string) (io.WriterTo, error) {
+	c, err := draw.NewFormattedCanvas(w, h, format)
+	if err != nil {
+		return nil, err
+	}
+	g.Draw(draw.New(c))
+	return c, nil
+}
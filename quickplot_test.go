@@ -0,0 +1,92 @@
+package plotext
+
+import (
+	"bytes"
+	"testing"
+
+	"gonum.org/v1/plot/vg"
+)
+
+func TestQuickPlot(t *testing.T) {
+	buffers := []*SampleBuffer{
+		sineBuffer(10000, 1000, 3, 1),
+		sineBuffer(10000, 1000, 5, 0.5),
+	}
+
+	p, err := QuickPlot(buffers,
+		WithTitle("test"),
+		WithAxisLabels("time (s)", "amplitude"),
+		WithDim(4*vg.Inch, 3*vg.Inch),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Title.Text != "test" || p.X.Label.Text != "time (s)" || p.Y.Label.Text != "amplitude" {
+		t.Error("options were not applied")
+	}
+
+	w, err := p.WriterTo(4*vg.Inch, 3*vg.Inch, "png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := w.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() == 0 {
+		t.Error("expected rendered output")
+	}
+}
+
+func TestGridPlot(t *testing.T) {
+	buffers := []*SampleBuffer{
+		sineBuffer(10000, 1000, 3, 1),
+		sineBuffer(5000, 1000, 5, 0.5),
+		sineBuffer(10000, 1000, 7, 2),
+	}
+
+	g, err := GridPlot(buffers, 2, 2, 6*vg.Inch, 4*vg.Inch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := 0
+	for _, row := range g.Plots {
+		for _, p := range row {
+			if p == nil {
+				continue
+			}
+			n++
+			if p.X.Min != g.Plots[0][0].X.Min || p.X.Max != g.Plots[0][0].X.Max {
+				t.Errorf("got X range [%v, %v], expected the shared [%v, %v]", p.X.Min, p.X.Max, g.Plots[0][0].X.Min, g.Plots[0][0].X.Max)
+			}
+			// the tickers are sized to a 3x2 inch tile, less padding
+			x, y := p.X.Tick.Marker.(AutoTicker), p.Y.Tick.Marker.(AutoTicker)
+			if x.Dim >= 3*vg.Inch || x.Dim < 2.5*vg.Inch || y.Dim >= 2*vg.Inch || y.Dim < 1.5*vg.Inch {
+				t.Errorf("got ticker dims %v x %v, expected about a tile", x.Dim, y.Dim)
+			}
+		}
+	}
+	if n != len(buffers) {
+		t.Errorf("got %d subplots, expected %d", n, len(buffers))
+	}
+	if g.Plots[1][1] != nil {
+		t.Error("expected the last cell to be empty")
+	}
+
+	w, err := g.WriterTo(6*vg.Inch, 4*vg.Inch, "png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := w.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() == 0 {
+		t.Error("expected rendered output")
+	}
+
+	if _, err := GridPlot(buffers, 1, 2, 6*vg.Inch, 4*vg.Inch); err == nil {
+		t.Error("expected an error for too many buffers")
+	}
+}
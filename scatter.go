@@ -0,0 +1,56 @@
+package plotext
+
+import (
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// QuantizedScatter is a plotter.Scatter derivative that, like QuantizedLine,
+// reduces the data when there are many more points than the canvas can show.
+// It then draws one marker per 1 vg.Point wide bucket of X, at the mean Y of
+// the points in the bucket, instead of overplotting every point.
+type QuantizedScatter struct {
+	*plotter.Scatter
+
+	// PointsPerUnit is the number of points per vg.Point of canvas width above
+	// which the data is reduced. 2 is used if it is zero.
+	PointsPerUnit float64
+}
+
+// NewQuantizedScatter returns a QuantizedScatter for the given points that uses
+// the default glyph style, mirroring plotter.NewScatter.
+func NewQuantizedScatter(xyer plotter.XYer) (*QuantizedScatter, error) {
+	sc, err := plotter.NewScatter(xyer)
+	if err != nil {
+		return nil, err
+	}
+	return &QuantizedScatter{Scatter: sc}, nil
+}
+
+// Plot draws the markers to a `draw.Canvas`. If there are more than
+// PointsPerUnit points per Canvas Point of width, a marker is drawn at the
+// smallest X and mean Y of each bucket of points per width Point. Otherwise,
+// the Scatter is plotted as-is.
+func (qs *QuantizedScatter) Plot(c draw.Canvas, plt *plot.Plot) {
+	dx := int(c.Max.X - c.Min.X)
+	if dx <= 0 || float64(len(qs.Scatter.XYs)) <= float64(dx)*qs.pointsPerUnit() {
+		qs.Scatter.Plot(c, plt)
+		return
+	}
+
+	e := aggregate(qs.Scatter.XYs, dx, plt.X.Min, plt.X.Max)
+
+	// draw from a copy so the original data is kept intact for subsequent
+	// draws
+	sc := *qs.Scatter
+	sc.XYs = e.means
+	sc.Plot(c, plt)
+}
+
+func (qs *QuantizedScatter) pointsPerUnit() float64 {
+	if qs.PointsPerUnit == 0 {
+		return 2
+	}
+	return qs.PointsPerUnit
+}
@@ -0,0 +1,47 @@
+package plotext
+
+import (
+	"image/color"
+	"testing"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/recorder"
+)
+
+func TestQuantizedScatter(t *testing.T) {
+	markers := func(points int, width float64) int {
+		xys := make(plotter.XYs, points)
+		for i := range xys {
+			xys[i] = plotter.XY{X: float64(i) / float64(points), Y: float64(i % 7)}
+		}
+		qs, err := NewQuantizedScatter(xys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		qs.GlyphStyle.Color = color.RGBA{G: 0xff, A: 0xff}
+		qs.GlyphStyle.Shape = draw.CircleGlyph{}
+
+		p := plot.New()
+		p.X.Min, p.X.Max, p.Y.Min, p.Y.Max = 0, 1, 0, 6
+		rec := new(recorder.Canvas)
+		qs.Plot(draw.NewCanvas(rec, vg.Length(width), 100), p)
+		return len(filledPaths(rec, qs.GlyphStyle.Color))
+	}
+
+	// reduced to one marker per bucket
+	for _, points := range []int{10000, 100000} {
+		for _, width := range []float64{50, 200} {
+			if n := markers(points, width); n != int(width) {
+				t.Errorf("%d points at width %v: got %d markers, expected %v", points, width, n, width)
+			}
+		}
+	}
+
+	// few enough points to draw
+	if n := markers(80, 50); n != 80 {
+		t.Errorf("got %d markers for 80 points, expected all of them", n)
+	}
+}
@@ -0,0 +1,119 @@
+package plotext
+
+import (
+	"math"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// SimplifiedLine is a plotter.Line derivative that drops points before drawing
+// using the Ramer–Douglas–Peucker algorithm in canvas space, so that the drawn
+// line stays within Tolerance of the original. Unlike QuantizedLine, this
+// preserves the shape of the line rather than its envelope, which suits
+// smooth dense data better than noisy data.
+type SimplifiedLine struct {
+	*plotter.Line
+
+	// Tolerance is the largest distance on the canvas that the drawn line may
+	// deviate from the original line. 0.5 vg.Points is used if it is zero.
+	Tolerance vg.Length
+}
+
+// NewSimplifiedLine returns a SimplifiedLine for the given points that uses
+// the default line style, mirroring plotter.NewLine.
+func NewSimplifiedLine(xyer plotter.XYer) (*SimplifiedLine, error) {
+	line, err := plotter.NewLine(xyer)
+	if err != nil {
+		return nil, err
+	}
+	return &SimplifiedLine{Line: line}, nil
+}
+
+// Plot draws the simplified line to a `draw.Canvas`. Any StepStyle applies to
+// the retained points.
+func (sl *SimplifiedLine) Plot(c draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&c)
+	pts := make([]vg.Point, len(sl.Line.XYs))
+	for i, p := range sl.Line.XYs {
+		pts[i] = vg.Point{X: trX(p.X), Y: trY(p.Y)}
+	}
+
+	keep := simplify(pts, sl.tolerance())
+	xys := make(plotter.XYs, len(keep))
+	for i, j := range keep {
+		xys[i] = sl.Line.XYs[j]
+	}
+
+	// draw from a copy so the original data is kept intact for subsequent
+	// draws
+	line := *sl.Line
+	line.XYs = xys
+	line.Plot(c, plt)
+}
+
+func (sl *SimplifiedLine) tolerance() vg.Length {
+	if sl.Tolerance == 0 {
+		return 0.5
+	}
+	return sl.Tolerance
+}
+
+// simplify returns the indices, in order, of the points of pts retained by the
+// Ramer–Douglas–Peucker algorithm with the given tolerance. The first and last
+// points are always retained.
+func simplify(pts []vg.Point, tol vg.Length) []int {
+	if len(pts) <= 2 {
+		keep := make([]int, len(pts))
+		for i := range keep {
+			keep[i] = i
+		}
+		return keep
+	}
+
+	retained := make([]bool, len(pts))
+	retained[0], retained[len(pts)-1] = true, true
+
+	// spans still to check, instead of recursing, so long lines can't overflow
+	// the stack
+	spans := [][2]int{{0, len(pts) - 1}}
+	for len(spans) > 0 {
+		span := spans[len(spans)-1]
+		spans = spans[:len(spans)-1]
+		a, b := span[0], span[1]
+
+		far, dist := -1, tol
+		for i := a + 1; i < b; i++ {
+			if d := segmentDistance(pts[i], pts[a], pts[b]); d > dist {
+				far, dist = i, d
+			}
+		}
+		if far < 0 {
+			continue
+		}
+		retained[far] = true
+		spans = append(spans, [2]int{a, far}, [2]int{far, b})
+	}
+
+	var keep []int
+	for i, r := range retained {
+		if r {
+			keep = append(keep, i)
+		}
+	}
+	return keep
+}
+
+// segmentDistance returns the distance from p to the line segment from a to b.
+func segmentDistance(p, a, b vg.Point) vg.Length {
+	ab, ap := b.Sub(a), p.Sub(a)
+	l2 := ab.Dot(ab)
+	if l2 == 0 {
+		return vg.Length(math.Hypot(float64(ap.X), float64(ap.Y)))
+	}
+	t := max(0, min(1, ap.Dot(ab)/l2))
+	d := ap.Sub(ab.Scale(t))
+	return vg.Length(math.Hypot(float64(d.X), float64(d.Y)))
+}
@@ -0,0 +1,68 @@
+package plotext
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/recorder"
+)
+
+func TestSimplify(t *testing.T) {
+	line := make([]vg.Point, 50)
+	for i := range line {
+		line[i] = vg.Point{X: vg.Length(i), Y: vg.Length(2 * i)}
+	}
+	if got := simplify(line, 0.5); !slices.Equal(got, []int{0, 49}) {
+		t.Errorf("collinear: got %v, expected the endpoints", got)
+	}
+
+	// a peak in the middle, plus a wobble smaller than the tolerance
+	peak := make([]vg.Point, 21)
+	for i := range peak {
+		y := 10 - math.Abs(float64(i-10))
+		if i == 5 {
+			y += 0.3
+		}
+		peak[i] = vg.Point{X: vg.Length(i), Y: vg.Length(y)}
+	}
+	if got := simplify(peak, 0.5); !slices.Equal(got, []int{0, 10, 20}) {
+		t.Errorf("peak: got %v, expected [0 10 20]", got)
+	}
+	if got := simplify(peak, 0.1); !slices.Contains(got, 5) {
+		t.Errorf("peak: got %v, expected the wobble kept at a finer tolerance", got)
+	}
+}
+
+func TestSimplifiedLine(t *testing.T) {
+	xys := make(plotter.XYs, 1000)
+	for i := range xys {
+		xys[i] = plotter.XY{X: float64(i), Y: float64(i) / 2}
+	}
+	sl, err := NewSimplifiedLine(xys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := plot.New()
+	p.X.Min, p.X.Max, p.Y.Min, p.Y.Max = 0, 999, 0, 499.5
+	rec := new(recorder.Canvas)
+	sl.Plot(draw.NewCanvas(rec, 300, 200), p)
+
+	var strokes []vg.Path
+	for _, a := range rec.Actions {
+		if s, ok := a.(*recorder.Stroke); ok {
+			strokes = append(strokes, s.Path)
+		}
+	}
+	if len(strokes) != 1 || len(strokes[0]) != 2 {
+		t.Errorf("got strokes %v, expected a single segment", strokes)
+	}
+	if sl.Line.XYs.Len() != 1000 {
+		t.Errorf("drawing changed the data to %d points", sl.Line.XYs.Len())
+	}
+}
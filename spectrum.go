@@ -0,0 +1,203 @@
+package plotext
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"math/cmplx"
+	"slices"
+
+	"gonum.org/v1/plot/plotter"
+)
+
+// Spectrum returns the one-sided magnitude spectrum of the samples, with X in
+// Hz and Y the magnitude of each frequency bin from DC to the Nyquist
+// frequency. The samples are zero-padded to the next power of two in length
+// before the FFT. Magnitudes are scaled by 2/len(Samples) (1/len(Samples) for
+// DC and Nyquist) so that a sinusoid of amplitude A shows up with a peak of
+// about A.
+func (s *SampleBuffer) Spectrum() plotter.XYer {
+	n := len(s.Samples)
+	if n == 0 {
+		return plotter.XYs{}
+	}
+
+	x := make([]complex128, nextPow2(n))
+	for i, v := range s.Samples {
+		x[i] = complex(v, 0)
+	}
+	fft(x)
+
+	bins := len(x)/2 + 1
+	df := s.SampleRate / float64(len(x))
+
+	ret := make(plotter.XYs, bins)
+	for k := range ret {
+		scale := 2 / float64(n)
+		if k == 0 || k == len(x)/2 {
+			scale = 1 / float64(n)
+		}
+		ret[k] = plotter.XY{X: float64(k) * df, Y: cmplx.Abs(x[k]) * scale}
+	}
+
+	return ret
+}
+
+// DominantFrequency returns the frequency in Hz of the largest peak of the
+// Spectrum of s, ignoring DC, or NaN if there are too few samples to have one.
+// The peak is refined to a fraction of a bin by fitting a parabola through the
+// peak bin and its neighbors.
+func (s *SampleBuffer) DominantFrequency() float64 {
+	spec := s.Spectrum().(plotter.XYs)
+	if len(spec) < 2 {
+		return math.NaN()
+	}
+
+	k := 1
+	for i := 2; i < len(spec); i++ {
+		if spec[i].Y > spec[k].Y {
+			k = i
+		}
+	}
+
+	f := spec[k].X
+	if k+1 < len(spec) {
+		a, b, c := spec[k-1].Y, spec[k].Y, spec[k+1].Y
+		if d := a - 2*b + c; d < 0 {
+			df := spec[1].X - spec[0].X
+			f += 0.5 * (a - c) / d * df
+		}
+	}
+	return f
+}
+
+// nextPow2 returns the smallest power of two that is at least n.
+func nextPow2(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	return 1 << bits.Len(uint(n-1))
+}
+
+// fft computes the discrete Fourier transform of x in place. len(x) must be a
+// power of two.
+func fft(x []complex128) {
+	n := len(x)
+	shift := bits.UintSize - bits.Len(uint(n-1))
+
+	// bit-reversal permutation
+	for i := range x {
+		j := int(bits.Reverse(uint(i)) >> shift)
+		if n > 1 && i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				a, b := x[start+k], w*x[start+k+size/2]
+				x[start+k], x[start+k+size/2] = a+b, a-b
+				w *= step
+			}
+		}
+	}
+}
+
+// ifft computes the inverse discrete Fourier transform of x in place, scaled
+// by 1/len(x). len(x) must be a power of two.
+func ifft(x []complex128) {
+	for i, v := range x {
+		x[i] = cmplx.Conj(v)
+	}
+	fft(x)
+	scale := complex(1/float64(len(x)), 0)
+	for i, v := range x {
+		x[i] = cmplx.Conj(v) * scale
+	}
+}
+
+// crossCorrelation returns sum_i a[i+k]*b[i] for every lag k at which the
+// sequences overlap, from -(len(b)-1) at index 0 to len(a)-1 at the end. It is
+// computed by FFT in O(n log n).
+func crossCorrelation(a, b []float64) []float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+
+	n := nextPow2(len(a) + len(b) - 1)
+	fa := make([]complex128, n)
+	fb := make([]complex128, n)
+	for i, v := range a {
+		fa[i] = complex(v, 0)
+	}
+	for i, v := range b {
+		fb[i] = complex(v, 0)
+	}
+	fft(fa)
+	fft(fb)
+	for i := range fa {
+		fa[i] *= cmplx.Conj(fb[i])
+	}
+	ifft(fa)
+
+	// negative lags wrap around to the end
+	ret := make([]float64, 0, len(a)+len(b)-1)
+	for k := -(len(b) - 1); k < len(a); k++ {
+		ret = append(ret, real(fa[(k+n)%n]))
+	}
+	return ret
+}
+
+// AlignBuffers finds the delay of test relative to ref, in samples, that
+// maximizes their cross-correlation, so that test[i] best matches
+// ref[i-lagSamples]. The cross-correlation is computed by FFT in O(n log n)
+// rather than directly in O(n²), which matters for long captures. The aligned
+// buffer is a copy of test with time coordinates shifted to line up with ref;
+// no samples are dropped or padded. AlignBuffers panics if the buffers have
+// different sample rates.
+func AlignBuffers(ref, test *SampleBuffer) (lagSamples int, aligned *SampleBuffer) {
+	if ref.SampleRate != test.SampleRate {
+		panic(fmt.Sprintf("plotext: can't align buffers with sample rates %g and %g", ref.SampleRate, test.SampleRate))
+	}
+
+	best := math.Inf(-1)
+	for i, r := range crossCorrelation(ref.Samples, test.Samples) {
+		if r > best {
+			// index i is lag k = i-(len(test)-1) of ref against test
+			best, lagSamples = r, len(test.Samples)-1-i
+		}
+	}
+
+	return lagSamples, &SampleBuffer{
+		Samples:    slices.Clone(test.Samples),
+		SampleRate: test.SampleRate,
+		StartIndex: ref.StartIndex - lagSamples,
+		TimeOffset: ref.TimeOffset,
+		IndexMode:  ref.IndexMode,
+	}
+}
+
+// CrossCorrelate returns the cross-correlation of a and b against the delay of
+// b relative to a in seconds, with the same sign as AlignBuffers, so a copy of
+// a delayed by d peaks at X = d. The lags run in increasing order from
+// -(a.Len()-1) to b.Len()-1 samples, through zero where the buffers line up
+// index for index. Y is the unnormalized sum of products of the overlapping
+// samples. It is an error for the buffers to have different sample rates.
+func CrossCorrelate(a, b *SampleBuffer) (plotter.XYs, error) {
+	if a.SampleRate != b.SampleRate {
+		return nil, fmt.Errorf("plotext: can't cross-correlate buffers with sample rates %g and %g", a.SampleRate, b.SampleRate)
+	}
+
+	corr := crossCorrelation(a.Samples, b.Samples)
+	ret := make(plotter.XYs, len(corr))
+	for i := range ret {
+		// a lag k of a against b is a delay of -k for b, so walk corr
+		// backward
+		ret[i].X = float64(i-(len(a.Samples)-1)) / a.SampleRate
+		ret[i].Y = corr[len(corr)-1-i]
+	}
+	return ret, nil
+}
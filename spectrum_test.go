@@ -0,0 +1,176 @@
+package plotext
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSpectrum(t *testing.T) {
+	const (
+		fs        = 1024.0
+		freq      = 64.0
+		amplitude = 2.0
+	)
+
+	// 1000 samples get padded to 1024, so bins are 1 Hz apart
+	s := sineBuffer(1000, fs, freq, amplitude)
+	spec := s.Spectrum()
+
+	if spec.Len() != 513 {
+		t.Fatalf("got %d bins, expected 513", spec.Len())
+	}
+	if x, _ := spec.XY(spec.Len() - 1); x != fs/2 {
+		t.Errorf("got last bin at %f Hz, expected %f Hz", x, fs/2)
+	}
+
+	var x, y float64
+	for i := 0; i < spec.Len(); i++ {
+		if bx, by := spec.XY(i); by > y {
+			x, y = bx, by
+		}
+	}
+
+	if x != freq {
+		t.Errorf("got peak at %f Hz, expected %f Hz", x, freq)
+	}
+	if math.Abs(y-amplitude) > 0.1*amplitude {
+		t.Errorf("got peak magnitude %f, expected about %f", y, amplitude)
+	}
+}
+
+func TestFFT(t *testing.T) {
+	x := []complex128{1, 2, 3, 4, 0, 0, 0, 0}
+	ex := make([]complex128, len(x))
+	for k := range ex {
+		for n, v := range x {
+			ex[k] += v * complex(math.Cos(2*math.Pi*float64(k*n)/8), -math.Sin(2*math.Pi*float64(k*n)/8))
+		}
+	}
+
+	fft(x)
+	for k := range x {
+		if d := x[k] - ex[k]; math.Hypot(real(d), imag(d)) > 1e-9 {
+			t.Errorf("bin %d: got %v, expected %v", k, x[k], ex[k])
+		}
+	}
+}
+
+func TestCrossCorrelation(t *testing.T) {
+	a := []float64{1, 2, 3}
+	b := []float64{4, 5}
+
+	// direct sums for lags -1 through 2
+	ex := []float64{5, 14, 23, 12}
+	got := crossCorrelation(a, b)
+	if len(got) != len(ex) {
+		t.Fatalf("got %v, expected %v", got, ex)
+	}
+	for i := range ex {
+		if math.Abs(got[i]-ex[i]) > 1e-9 {
+			t.Errorf("lag %d: got %v, expected %v", i-1, got[i], ex[i])
+		}
+	}
+}
+
+func TestAlignBuffers(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	ref := &SampleBuffer{Samples: make([]float64, 500), SampleRate: 1000, TimeOffset: 2}
+	for i := range ref.Samples {
+		ref.Samples[i] = rng.NormFloat64()
+	}
+
+	for _, lag := range []int{0, 37, -12} {
+		test := &SampleBuffer{Samples: make([]float64, len(ref.Samples)), SampleRate: 1000}
+		for i := range test.Samples {
+			if j := i - lag; j >= 0 && j < len(ref.Samples) {
+				test.Samples[i] = ref.Samples[j]
+			}
+		}
+
+		got, aligned := AlignBuffers(ref, test)
+		if got != lag {
+			t.Errorf("got lag %d, expected %d", got, lag)
+			continue
+		}
+
+		// sample i of test lands on the X of sample i-lag of ref
+		i := 100
+		x, y := aligned.XY(i)
+		rx, ry := ref.XY(i - lag)
+		if math.Abs(x-rx) > 1e-12 || y != ry {
+			t.Errorf("lag %d: aligned sample %d at (%v, %v), expected (%v, %v)", lag, i, x, y, rx, ry)
+		}
+	}
+}
+
+func TestCrossCorrelate(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	a := &SampleBuffer{Samples: make([]float64, 300), SampleRate: 100}
+	for i := range a.Samples {
+		a.Samples[i] = rng.NormFloat64()
+	}
+
+	for _, lag := range []int{0, 25, -8} {
+		b := &SampleBuffer{Samples: make([]float64, 200), SampleRate: 100}
+		for i := range b.Samples {
+			if j := i - lag; j >= 0 && j < len(a.Samples) {
+				b.Samples[i] = a.Samples[j]
+			}
+		}
+
+		xys, err := CrossCorrelate(a, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(a.Samples) + len(b.Samples) - 1; len(xys) != n {
+			t.Fatalf("got %d lags, expected %d", len(xys), n)
+		}
+		if xys[0].X != -2.99 || xys[len(xys)-1].X != 1.99 {
+			t.Errorf("got lags from %v to %v, expected -2.99 to 1.99", xys[0].X, xys[len(xys)-1].X)
+		}
+
+		peak := 0
+		for i := range xys {
+			if xys[i].Y > xys[peak].Y {
+				peak = i
+			}
+		}
+		if ex := float64(lag) / 100; math.Abs(xys[peak].X-ex) > 1e-12 {
+			t.Errorf("got peak at %v s, expected %v s", xys[peak].X, ex)
+		}
+	}
+
+	if _, err := CrossCorrelate(a, &SampleBuffer{SampleRate: 50}); err == nil {
+		t.Error("expected an error for mismatched sample rates")
+	}
+}
+
+func TestDominantFrequency(t *testing.T) {
+	const fs = 1000.0
+	s := sineBuffer(1000, fs, 137, 1)
+	df := fs / 1024
+
+	f := s.DominantFrequency()
+	if math.Abs(f-137) > df/2 {
+		t.Errorf("got %f Hz, expected 137 Hz within half a bin (%f Hz)", f, df/2)
+	}
+
+	// the interpolated estimate beats the nearest bin
+	bin := math.Round(137/df) * df
+	if math.Abs(f-137) >= math.Abs(bin-137) {
+		t.Errorf("got %f Hz, no closer to 137 Hz than the peak bin at %f Hz", f, bin)
+	}
+
+	// a large DC offset doesn't count
+	for i := range s.Samples {
+		s.Samples[i] += 10
+	}
+	if f := s.DominantFrequency(); math.Abs(f-137) > df/2 {
+		t.Errorf("with DC offset: got %f Hz, expected about 137 Hz", f)
+	}
+
+	if f := (&SampleBuffer{Samples: []float64{1}, SampleRate: fs}).DominantFrequency(); !math.IsNaN(f) {
+		t.Errorf("got %f Hz for one sample, expected NaN", f)
+	}
+}
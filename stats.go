@@ -0,0 +1,262 @@
+package plotext
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// Min returns the smallest sample value, or NaN if the buffer has no finite
+// samples. NaN and infinite samples mark gaps in the data and are skipped, here
+// and in the other statistics, as in Histogram.
+func (s *SampleBuffer) Min() float64 {
+	m := math.Inf(1)
+	for _, v := range s.Samples {
+		if isFinite(v) {
+			m = min(m, v)
+		}
+	}
+	if math.IsInf(m, 1) {
+		return math.NaN()
+	}
+	return m
+}
+
+// Max returns the largest sample value, or NaN if the buffer has no finite
+// samples.
+func (s *SampleBuffer) Max() float64 {
+	m := math.Inf(-1)
+	for _, v := range s.Samples {
+		if isFinite(v) {
+			m = max(m, v)
+		}
+	}
+	if math.IsInf(m, -1) {
+		return math.NaN()
+	}
+	return m
+}
+
+// Mean returns the arithmetic mean of the finite samples, or NaN if there are
+// none.
+func (s *SampleBuffer) Mean() float64 {
+	sum, n := 0.0, 0
+	for _, v := range s.Samples {
+		if isFinite(v) {
+			sum += v
+			n++
+		}
+	}
+	return sum / float64(n)
+}
+
+// RMS returns the root mean square of the finite samples, or NaN if there are
+// none.
+func (s *SampleBuffer) RMS() float64 {
+	sum, n := 0.0, 0
+	for _, v := range s.Samples {
+		if isFinite(v) {
+			sum += v * v
+			n++
+		}
+	}
+	return math.Sqrt(sum / float64(n))
+}
+
+// SNR returns the signal-to-noise ratio of s in dB, 20*log10 of the ratio of
+// the RMS of s to that of noise, such as a capture of the noise floor with the
+// signal off. Use SliceTime to take the noise from a quiet stretch of the same
+// capture. It returns +Inf if the noise RMS is zero, and NaN if either buffer
+// is empty. SNR panics if the buffers have different sample rates.
+func (s *SampleBuffer) SNR(noise *SampleBuffer) float64 {
+	if s.SampleRate != noise.SampleRate {
+		panic(fmt.Sprintf("plotext: can't compare buffers with sample rates %g and %g", s.SampleRate, noise.SampleRate))
+	}
+
+	signalRMS, noiseRMS := s.RMS(), noise.RMS()
+	if noiseRMS == 0 {
+		return math.Inf(1)
+	}
+	return 20 * math.Log10(signalRMS/noiseRMS)
+}
+
+// Histogram divides the range of the samples into `bins` bins of equal width
+// and returns the center of each bin as X and the number of samples falling
+// into it as Y. NaN and infinite samples are left out, both of the range and
+// of the counts. It is an error for bins to be non-positive or for the buffer
+// to have no finite samples.
+func (s *SampleBuffer) Histogram(bins int) (plotter.XYs, error) {
+	if bins <= 0 {
+		return nil, fmt.Errorf("plotext: invalid histogram bin count %d", bins)
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range s.Samples {
+		if isFinite(v) {
+			lo, hi = min(lo, v), max(hi, v)
+		}
+	}
+	if lo > hi {
+		return nil, errors.New("plotext: histogram of buffer without finite samples")
+	}
+	width := (hi - lo) / float64(bins)
+
+	ret := make(plotter.XYs, bins)
+	for i := range ret {
+		ret[i].X = lo + (float64(i)+0.5)*width
+	}
+
+	for _, v := range s.Samples {
+		if !isFinite(v) {
+			continue
+		}
+		i := 0
+		if width > 0 {
+			i = max(0, min(int((v-lo)/width), bins-1))
+		}
+		ret[i].Y++
+	}
+
+	return ret, nil
+}
+
+// ClippedRegions returns the index ranges [start, end) of consecutive samples
+// where |sample| >= fullScale, such as where an ADC saturated. Each range can
+// be passed directly to Window.
+func (s *SampleBuffer) ClippedRegions(fullScale float64) [][2]int {
+	var ret [][2]int
+	start := -1
+	for i, v := range s.Samples {
+		clipped := math.Abs(v) >= fullScale
+		if clipped && start < 0 {
+			start = i
+		} else if !clipped && start >= 0 {
+			ret = append(ret, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		ret = append(ret, [2]int{start, len(s.Samples)})
+	}
+	return ret
+}
+
+// EstimateFrequency estimates the fundamental frequency of s in Hz by counting
+// zero crossings. A crossing only counts once the signal has gone past
+// hysteresis on the other side of zero, so noise smaller than that around
+// zero is ignored. The estimate is the number of whole periods between the
+// first and last upward crossings divided by the time between them, or 0 if
+// there are fewer than two upward crossings.
+func (s *SampleBuffer) EstimateFrequency(hysteresis float64) float64 {
+	hysteresis = math.Abs(hysteresis)
+
+	state := 0 // sign of the signal the last time it was past the threshold
+	first, last, rising := -1, -1, 0
+	for i, v := range s.Samples {
+		switch {
+		case v > hysteresis:
+			if state < 0 {
+				if first < 0 {
+					first = i
+				}
+				last = i
+				rising++
+			}
+			state = 1
+		case v < -hysteresis:
+			state = -1
+		}
+	}
+
+	if rising < 2 {
+		return 0
+	}
+	return float64(rising-1) * s.SampleRate / float64(last-first)
+}
+
+// FindPeaks returns the time and amplitude of each local maximum with a
+// prominence greater than minProminence, suitable for a plotter.Scatter.
+//
+// The prominence of a peak is its height above the higher of the two lowest
+// points between it and the nearest higher sample on either side (or the end
+// of the buffer, if there is no higher sample on that side). Peaks on a flat
+// top are reported at the first sample of the plateau. The first and last
+// samples are never peaks.
+func (s *SampleBuffer) FindPeaks(minProminence float64) plotter.XYs {
+	p := s.Samples
+	leftBase, rightBase := peakBases(p)
+
+	var ret plotter.XYs
+	for i := 1; i < len(p)-1; i++ {
+		if !(p[i] > p[i-1]) {
+			continue
+		}
+
+		// walk across a plateau
+		j := i
+		for j+1 < len(p) && p[j+1] == p[i] {
+			j++
+		}
+		if j+1 == len(p) || p[j+1] > p[i] {
+			i = j
+			continue
+		}
+
+		if p[i]-max(leftBase[i], rightBase[j]) > minProminence {
+			x, y := s.XY(i)
+			ret = append(ret, plotter.XY{X: x, Y: y})
+		}
+		i = j
+	}
+
+	return ret
+}
+
+// peakBases returns, for each sample, the lowest point between it and the
+// nearest higher sample to its left and to its right (or the end of p), in
+// O(n). Each side keeps a stack of samples in strictly decreasing order, along
+// with the minimum of the samples between each one and the one below it.
+func peakBases(p []float64) (left, right []float64) {
+	type entry struct{ v, segMin float64 }
+
+	// scan fills base visiting the samples from start in steps of step
+	scan := func(base []float64, start, step int) {
+		var stack []entry
+		for i := start; i >= 0 && i < len(p); i += step {
+			m := p[i]
+			for len(stack) > 0 && stack[len(stack)-1].v <= p[i] {
+				m = min(m, stack[len(stack)-1].segMin)
+				stack = stack[:len(stack)-1]
+			}
+			base[i] = m
+			stack = append(stack, entry{v: p[i], segMin: m})
+		}
+	}
+
+	left = make([]float64, len(p))
+	right = make([]float64, len(p))
+	scan(left, 0, 1)
+	scan(right, len(p)-1, -1)
+	return left, right
+}
+
+// ScatterFromPeaks returns a plotter.Scatter marking the peaks of s found by
+// FindPeaks with the given minimum prominence. The markers are red triangles
+// by default, and can be restyled through GlyphStyle.
+func ScatterFromPeaks(s *SampleBuffer, minProminence float64) (*plotter.Scatter, error) {
+	sc, err := plotter.NewScatter(s.FindPeaks(minProminence))
+	if err != nil {
+		return nil, err
+	}
+	sc.GlyphStyle = draw.GlyphStyle{
+		Color:  color.RGBA{R: 0xd0, A: 0xff},
+		Radius: vg.Points(3),
+		Shape:  draw.TriangleGlyph{},
+	}
+	return sc, nil
+}
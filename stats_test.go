@@ -0,0 +1,314 @@
+package plotext
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"gonum.org/v1/plot/plotter"
+)
+
+// sineBuffer returns a SampleBuffer holding n samples of a sine wave with the
+// given amplitude and frequency.
+func sineBuffer(n int, fs, freq, amplitude float64) *SampleBuffer {
+	p := make([]float64, n)
+	for i := range p {
+		p[i] = amplitude * math.Sin(2*math.Pi*freq*float64(i)/fs)
+	}
+	return &SampleBuffer{Samples: p, SampleRate: fs}
+}
+
+func TestStats(t *testing.T) {
+	const amplitude = 3.0
+	s := sineBuffer(1000, 1000, 10, amplitude)
+
+	const tol = 1e-6
+	if v := s.Min(); math.Abs(v+amplitude) > tol {
+		t.Errorf("min: got %f, expected %f", v, -amplitude)
+	}
+	if v := s.Max(); math.Abs(v-amplitude) > tol {
+		t.Errorf("max: got %f, expected %f", v, amplitude)
+	}
+	if v := s.Mean(); math.Abs(v) > tol {
+		t.Errorf("mean: got %f, expected 0", v)
+	}
+	if v, ex := s.RMS(), amplitude/math.Sqrt2; math.Abs(v-ex) > tol {
+		t.Errorf("rms: got %f, expected %f", v, ex)
+	}
+
+	empty := &SampleBuffer{SampleRate: 1000}
+	for name, f := range map[string]func() float64{
+		"min":  empty.Min,
+		"max":  empty.Max,
+		"mean": empty.Mean,
+		"rms":  empty.RMS,
+	} {
+		if v := f(); !math.IsNaN(v) {
+			t.Errorf("%s of empty buffer: got %f, expected NaN", name, v)
+		}
+	}
+
+	// gaps are skipped, and a buffer of nothing but gaps is like an empty one
+	gappy := &SampleBuffer{Samples: []float64{math.NaN(), -2, math.Inf(1), 1, 4, math.Inf(-1)}, SampleRate: 1}
+	for name, row := range map[string]struct {
+		f  func() float64
+		ex float64
+	}{
+		"min":  {gappy.Min, -2},
+		"max":  {gappy.Max, 4},
+		"mean": {gappy.Mean, 1},
+		"rms":  {gappy.RMS, math.Sqrt(7)},
+	} {
+		if v := row.f(); math.Abs(v-row.ex) > tol {
+			t.Errorf("%s with gaps: got %f, expected %f", name, v, row.ex)
+		}
+	}
+	gaps := &SampleBuffer{Samples: []float64{math.NaN(), math.Inf(1)}, SampleRate: 1}
+	for name, f := range map[string]func() float64{
+		"min":  gaps.Min,
+		"max":  gaps.Max,
+		"mean": gaps.Mean,
+		"rms":  gaps.RMS,
+	} {
+		if v := f(); !math.IsNaN(v) {
+			t.Errorf("%s of all gaps: got %f, expected NaN", name, v)
+		}
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	s := &SampleBuffer{Samples: make([]float64, 1000), SampleRate: 1}
+	for i := range s.Samples {
+		s.Samples[i] = float64(i%100) / 99
+	}
+
+	hist, err := s.Histogram(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hist) != 10 {
+		t.Fatalf("got %d bins, expected 10", len(hist))
+	}
+
+	total := 0.0
+	for i, bin := range hist {
+		total += bin.Y
+		if ex := (float64(i) + 0.5) / 10; math.Abs(bin.X-ex) > 1e-12 {
+			t.Errorf("bin %d: got center %f, expected %f", i, bin.X, ex)
+		}
+		if bin.Y != 100 {
+			t.Errorf("bin %d: got count %f, expected 100", i, bin.Y)
+		}
+	}
+	if total != float64(len(s.Samples)) {
+		t.Errorf("got total count %f, expected %d", total, len(s.Samples))
+	}
+
+	if _, err := s.Histogram(0); err == nil {
+		t.Error("expected error for 0 bins")
+	}
+	if _, err := (&SampleBuffer{}).Histogram(10); err == nil {
+		t.Error("expected error for empty buffer")
+	}
+
+	// non-finite samples are neither binned nor stretch the range
+	s.Samples = append(s.Samples, math.Inf(1), math.NaN(), math.Inf(-1))
+	hist, err = s.Histogram(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, bin := range hist {
+		if ex := (float64(i) + 0.5) / 10; math.Abs(bin.X-ex) > 1e-12 || bin.Y != 100 {
+			t.Errorf("bin %d with non-finite samples: got %v, expected {%v 100}", i, bin, ex)
+		}
+	}
+	if _, err := (&SampleBuffer{Samples: []float64{math.NaN()}}).Histogram(10); err == nil {
+		t.Error("expected error for a buffer without finite samples")
+	}
+}
+
+func TestClippedRegions(t *testing.T) {
+	s := &SampleBuffer{
+		Samples:    []float64{0, 0.5, 1, 1, 1, 0.2, -0.3, -1, -1.2, 0, 0.99, 1},
+		SampleRate: 1,
+	}
+
+	ex := [][2]int{{2, 5}, {7, 9}, {11, 12}}
+	if got := s.ClippedRegions(1); !slices.Equal(got, ex) {
+		t.Errorf("got %v, expected %v", got, ex)
+	}
+
+	if got := s.ClippedRegions(2); len(got) != 0 {
+		t.Errorf("got %v, expected no clipped regions", got)
+	}
+}
+
+func TestFindPeaks(t *testing.T) {
+	const fs = 1000
+	s := &SampleBuffer{Samples: make([]float64, 1000), SampleRate: fs}
+	for i := range s.Samples {
+		x := float64(i) / fs
+		// humps at 0.25 s and 0.75 s, plus ripple
+		s.Samples[i] = math.Exp(-math.Pow((x-0.25)/0.05, 2)) +
+			0.8*math.Exp(-math.Pow((x-0.75)/0.05, 2)) +
+			0.02*math.Sin(2*math.Pi*40*x)
+	}
+
+	peaks := s.FindPeaks(0.1)
+	if len(peaks) != 2 {
+		t.Fatalf("got %d peaks, expected 2: %v", len(peaks), peaks)
+	}
+	for i, ex := range []float64{0.25, 0.75} {
+		if math.Abs(peaks[i].X-ex) > 0.01 {
+			t.Errorf("peak %d: got x %f, expected about %f", i, peaks[i].X, ex)
+		}
+	}
+
+	// with no threshold the ripple shows up too
+	if n := len(s.FindPeaks(0)); n <= 2 {
+		t.Errorf("got %d peaks with no prominence threshold, expected ripple peaks", n)
+	}
+}
+
+// referenceFindPeaks is the straightforward O(n²) version of FindPeaks that
+// walks out from each peak to find its bases, for checking the stack-based
+// implementation against.
+func referenceFindPeaks(s *SampleBuffer, minProminence float64) plotter.XYs {
+	p := s.Samples
+
+	var ret plotter.XYs
+	for i := 1; i < len(p)-1; i++ {
+		if !(p[i] > p[i-1]) {
+			continue
+		}
+		j := i
+		for j+1 < len(p) && p[j+1] == p[i] {
+			j++
+		}
+		if j+1 == len(p) || p[j+1] > p[i] {
+			i = j
+			continue
+		}
+
+		leftBase := p[i]
+		for k := i - 1; k >= 0 && p[k] <= p[i]; k-- {
+			leftBase = min(leftBase, p[k])
+		}
+		rightBase := p[i]
+		for k := j + 1; k < len(p) && p[k] <= p[i]; k++ {
+			rightBase = min(rightBase, p[k])
+		}
+
+		if p[i]-max(leftBase, rightBase) > minProminence {
+			x, y := s.XY(i)
+			ret = append(ret, plotter.XY{X: x, Y: y})
+		}
+		i = j
+	}
+	return ret
+}
+
+// noisyRamp returns a rising ramp with noise, on which walking out from each
+// peak to the nearest higher sample takes quadratic time.
+func noisyRamp(n int) *SampleBuffer {
+	rng := rand.New(rand.NewSource(1))
+	s := &SampleBuffer{Samples: make([]float64, n), SampleRate: 1000}
+	for i := range s.Samples {
+		s.Samples[i] = float64(i)/float64(n) + 0.1*rng.NormFloat64()
+	}
+	return s
+}
+
+func TestFindPeaksMatchesReference(t *testing.T) {
+	plateaus := &SampleBuffer{Samples: []float64{0, 2, 2, 1, 3, 3, 3, 0, 3, 1, 1, 4, 2, 2, 5}, SampleRate: 1}
+	for _, s := range []*SampleBuffer{noisyRamp(5000), sineBuffer(2000, 1000, 7, 1), plateaus} {
+		for _, prominence := range []float64{0, 0.05, 0.3, 1} {
+			if got, ex := s.FindPeaks(prominence), referenceFindPeaks(s, prominence); !slices.Equal(got, ex) {
+				t.Errorf("%d samples, prominence %v: got %d peaks, expected %d", s.Len(), prominence, len(got), len(ex))
+			}
+		}
+	}
+}
+
+func BenchmarkFindPeaks(b *testing.B) {
+	s := noisyRamp(1000000)
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		s.FindPeaks(0.1)
+	}
+}
+
+func TestScatterFromPeaks(t *testing.T) {
+	s := sineBuffer(1000, 1000, 5, 1)
+
+	for _, prominence := range []float64{0.5, 3} {
+		sc, err := ScatterFromPeaks(s, prominence)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, ex := sc.Len(), len(s.FindPeaks(prominence)); got != ex {
+			t.Errorf("prominence %v: got %d markers, expected %d", prominence, got, ex)
+		}
+		if sc.GlyphStyle.Shape == nil || sc.GlyphStyle.Radius <= 0 {
+			t.Errorf("prominence %v: got unstyled glyphs %+v", prominence, sc.GlyphStyle)
+		}
+	}
+}
+
+func TestEstimateFrequency(t *testing.T) {
+	const fs = 10000
+	if f := sineBuffer(fs, fs, 100, 1).EstimateFrequency(0.1); math.Abs(f-100) > 0.1 {
+		t.Errorf("got %f Hz, expected about 100 Hz", f)
+	}
+
+	s := sineBuffer(fs, fs, 10, 1)
+
+	// noise that crosses zero between the real crossings
+	rng := rand.New(rand.NewSource(1))
+	for i := range s.Samples {
+		s.Samples[i] += 0.05 * (rng.Float64()*2 - 1)
+	}
+
+	if f := s.EstimateFrequency(0.1); math.Abs(f-10) > 0.05 {
+		t.Errorf("got %f Hz, expected about 10 Hz", f)
+	}
+	if f := s.EstimateFrequency(0); f < 15 {
+		t.Errorf("got %f Hz without hysteresis, expected noise to inflate the estimate", f)
+	}
+
+	if f := (&SampleBuffer{Samples: []float64{1, 1, 1}, SampleRate: fs}).EstimateFrequency(0.1); f != 0 {
+		t.Errorf("got %f Hz for a constant signal, expected 0", f)
+	}
+}
+
+func TestSNR(t *testing.T) {
+	// a sine of amplitude 2 has RMS sqrt(2), and alternating ±0.01 noise has
+	// RMS 0.01, for 20*log10(100*sqrt(2)) dB
+	s := sineBuffer(1000, 1000, 10, 2)
+	noise := &SampleBuffer{Samples: make([]float64, 200), SampleRate: 1000}
+	for i := range noise.Samples {
+		noise.Samples[i] = 0.01
+		if i%2 == 1 {
+			noise.Samples[i] = -0.01
+		}
+	}
+
+	if got, ex := s.SNR(noise), 20*math.Log10(100*math.Sqrt2); math.Abs(got-ex) > 1e-9 {
+		t.Errorf("got SNR %v dB, expected %v dB", got, ex)
+	}
+
+	// a noise segment taken from a quiet stretch of the same capture
+	quiet := s.PadTo(1200, 0)
+	if got := s.SNR(quiet.SliceTime(1, 1.2)); !math.IsInf(got, 1) {
+		t.Errorf("got SNR %v dB against silence, expected +Inf", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for mismatched sample rates")
+		}
+	}()
+	s.SNR(&SampleBuffer{Samples: noise.Samples, SampleRate: 500})
+}
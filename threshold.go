@@ -0,0 +1,99 @@
+package plotext
+
+import (
+	"image/color"
+	"math"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/font"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/text"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// ThresholdLine is a plotter that draws a horizontal line at a Y value, or a
+// vertical line at an X value, across the whole data area, such as a trigger
+// level or a limit. An optional label is drawn at the right or top end of the
+// line.
+type ThresholdLine struct {
+	// Value is the Y value of a horizontal line or the X value of a vertical
+	// line.
+	Value float64
+
+	// Vertical makes the line vertical instead of horizontal.
+	Vertical bool
+
+	// LineStyle is the style of the line.
+	draw.LineStyle
+
+	// Label, if not empty, is drawn at the end of the line in TextStyle.
+	Label string
+
+	// TextStyle is the style of the label. Its alignment is set when drawing
+	// to keep the label clear of the line.
+	TextStyle text.Style
+}
+
+// NewThresholdLine returns a horizontal ThresholdLine at the given value,
+// drawn dashed in the given color with a label in the default font.
+func NewThresholdLine(value float64, label string, c color.Color) *ThresholdLine {
+	ls := plotter.DefaultLineStyle
+	ls.Color = c
+	ls.Dashes = []vg.Length{vg.Points(4), vg.Points(2)}
+
+	return &ThresholdLine{
+		Value:     value,
+		LineStyle: ls,
+		Label:     label,
+		TextStyle: text.Style{
+			Color:   c,
+			Font:    font.From(plotter.DefaultFont, plotter.DefaultFontSize),
+			Handler: plot.DefaultTextHandler,
+		},
+	}
+}
+
+// Plot draws the line and its label to a `draw.Canvas`, implementing the
+// plot.Plotter interface. Nothing is drawn if Value is outside of the axis
+// range.
+func (tl *ThresholdLine) Plot(c draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&c)
+	pad := tl.LineStyle.Width + vg.Points(2)
+	sty := tl.TextStyle
+
+	var label vg.Point
+	if tl.Vertical {
+		x := trX(tl.Value)
+		if x < c.Min.X || x > c.Max.X {
+			return
+		}
+		c.StrokeLine2(tl.LineStyle, x, c.Min.Y, x, c.Max.Y)
+		label = vg.Point{X: x + pad, Y: c.Max.Y}
+		sty.XAlign, sty.YAlign = draw.XLeft, draw.YTop
+	} else {
+		y := trY(tl.Value)
+		if y < c.Min.Y || y > c.Max.Y {
+			return
+		}
+		c.StrokeLine2(tl.LineStyle, c.Min.X, y, c.Max.X, y)
+		label = vg.Point{X: c.Max.X, Y: y + pad}
+		sty.XAlign, sty.YAlign = draw.XRight, draw.YBottom
+	}
+
+	if tl.Label != "" {
+		c.FillText(sty, label, tl.Label)
+	}
+}
+
+// DataRange returns Value as the range of the axis the line is positioned on,
+// and an empty range for the other axis, so that the line is in view without
+// affecting the extent of the other data, implementing the plot.DataRanger
+// interface.
+func (tl *ThresholdLine) DataRange() (xmin, xmax, ymin, ymax float64) {
+	inf := math.Inf(1)
+	if tl.Vertical {
+		return tl.Value, tl.Value, inf, -inf
+	}
+	return inf, -inf, tl.Value, tl.Value
+}
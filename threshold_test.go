@@ -0,0 +1,63 @@
+package plotext
+
+import (
+	"bytes"
+	"image/color"
+	"math"
+	"testing"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/recorder"
+)
+
+func TestThresholdLine(t *testing.T) {
+	tl := NewThresholdLine(0.5, "trigger", color.RGBA{R: 0xff, A: 0xff})
+
+	xmin, xmax, ymin, ymax := tl.DataRange()
+	if ymin != 0.5 || ymax != 0.5 || !math.IsInf(xmin, 1) || !math.IsInf(xmax, -1) {
+		t.Errorf("got range [%v, %v] x [%v, %v], expected an empty X range and Y at 0.5", xmin, xmax, ymin, ymax)
+	}
+
+	p := plot.New()
+	ql, err := NewQuantizedLine(sineBuffer(1000, 1000, 3, 0.2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Add(ql, tl)
+	if p.Y.Max < 0.5 {
+		t.Errorf("got Y axis max %v, expected the threshold in range", p.Y.Max)
+	}
+	if p.X.Min != 0 || p.X.Max != 0.999 {
+		t.Errorf("got X axis [%v, %v], expected the data's", p.X.Min, p.X.Max)
+	}
+
+	w, err := p.WriterTo(4*vg.Inch, 3*vg.Inch, "png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := w.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// the line spans the canvas
+	rec := new(recorder.Canvas)
+	c := draw.NewCanvas(rec, 200, 100)
+	tl.Plot(c, p)
+	var spans bool
+	for _, a := range rec.Actions {
+		if s, ok := a.(*recorder.Stroke); ok && len(s.Path) == 2 {
+			spans = s.Path[0].Pos.X == c.Min.X && s.Path[1].Pos.X == c.Max.X
+		}
+	}
+	if !spans {
+		t.Error("expected a stroke across the canvas")
+	}
+
+	tl.Vertical = true
+	if xmin, xmax, _, _ := tl.DataRange(); xmin != 0.5 || xmax != 0.5 {
+		t.Errorf("vertical: got X range [%v, %v], expected 0.5", xmin, xmax)
+	}
+}
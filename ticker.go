@@ -0,0 +1,197 @@
+package plotext
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/dustin/go-humanize"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/font"
+	"gonum.org/v1/plot/vg"
+)
+
+// LogTicker is a plot.Ticker for log-scaled axes. It places labeled major ticks
+// at each decade and unlabeled minor ticks at 2 through 9 times each decade.
+// If the range doesn't contain at least two decades, the minor ticks are
+// labeled too so that the axis can still be read.
+type LogTicker struct{}
+
+// Ticks returns Ticks in a specified range. It panics if min or max is not
+// positive.
+func (LogTicker) Ticks(min float64, max float64) []plot.Tick {
+	if min <= 0 || max <= 0 {
+		panic("plotext: values must be greater than 0 for a log scale")
+	}
+
+	// allow for floating point error at the range boundaries
+	const eps = 1e-9
+	lo := min * (1 - eps)
+	hi := max * (1 + eps)
+
+	minDecade := int(math.Floor(math.Log10(lo)))
+	maxDecade := int(math.Ceil(math.Log10(hi)))
+
+	var ret []plot.Tick
+	majors := 0
+	for d := minDecade; d <= maxDecade; d++ {
+		decade := math.Pow10(d)
+		for k := 1; k <= 9; k++ {
+			v := float64(k) * decade
+			if v < lo || v > hi {
+				continue
+			}
+			t := plot.Tick{Value: v}
+			if k == 1 {
+				t.Label = logLabel(v)
+				majors++
+			}
+			ret = append(ret, t)
+		}
+	}
+
+	if majors < 2 {
+		for i := range ret {
+			ret[i].Label = logLabel(ret[i].Value)
+		}
+	}
+
+	return ret
+}
+
+// logLabel formats a LogTicker label like AutoTicker does, with an SI prefix
+// only outside of [1, 1000). Each label gets its own prefix, since the labels
+// of a log axis span several of them.
+func logLabel(v float64) string {
+	return AutoTicker{}.formatLabel(v, 3, siExponent(v))
+}
+
+// TimeTicker is a plot.Ticker for axes in seconds, such as the X axis of a
+// SampleBuffer. Major ticks are placed at human-friendly intervals (1s, 5s,
+// 15s, 1m, 5m, 1h, ...) and labeled like "05:30", or like "1:23:45" on every
+// tick if the range reaches an hour. Ranges needing sub-second intervals are
+// labeled with SI prefixes instead, like "250 ms".
+type TimeTicker struct {
+	Dim vg.Length // length of the axis; 800 if zero
+}
+
+// timeIntervals lists the major tick intervals of at least one second that
+// TimeTicker can choose, along with the number of minor ticks per major tick.
+var timeIntervals = []struct {
+	seconds float64
+	minors  int
+}{
+	{1, 5},
+	{2, 4},
+	{5, 5},
+	{10, 5},
+	{15, 3},
+	{30, 6},
+	{60, 6},
+	{2 * 60, 4},
+	{5 * 60, 5},
+	{10 * 60, 5},
+	{15 * 60, 3},
+	{30 * 60, 6},
+	{3600, 6},
+	{2 * 3600, 4},
+	{3 * 3600, 3},
+	{6 * 3600, 6},
+	{12 * 3600, 4},
+	{24 * 3600, 4},
+}
+
+// Ticks returns Ticks in a specified range
+func (t TimeTicker) Ticks(min float64, max float64) []plot.Tick {
+	dim := t.Dim
+	if dim == 0 {
+		dim = 800
+	}
+
+	if !(max > min) {
+		return []plot.Tick{{Value: min, Label: formatTime(min, math.Abs(min) < 1, math.Abs(min) >= 3600)}}
+	}
+
+	// aim for about 1 label per inch
+	targetMajorTickCount := float64(dim / font.Inch)
+	target := (max - min) / targetMajorTickCount
+
+	var (
+		major  float64
+		minors int
+	)
+
+	if target < 1 {
+		major, minors = niceStep(target)
+	} else if last := timeIntervals[len(timeIntervals)-1]; target > last.seconds {
+		major, minors = niceStep(target / last.seconds)
+		major *= last.seconds
+	} else {
+		for _, in := range timeIntervals {
+			if in.seconds >= target {
+				major, minors = in.seconds, in.minors
+				break
+			}
+		}
+	}
+
+	minor := major / float64(minors)
+	subSecond := major < 1
+	// every label has an hours field if any of them needs one
+	hours := math.Max(math.Abs(min), math.Abs(max)) >= 3600
+
+	minTickIndex := int(math.Ceil(snapIndex(min / minor)))
+	maxTickIndex := int(math.Floor(snapIndex(max / minor)))
+
+	ret := make([]plot.Tick, 0, maxTickIndex-minTickIndex+1)
+	for i := minTickIndex; i <= maxTickIndex; i++ {
+		t := plot.Tick{
+			Value: tickValue(i, minor),
+		}
+		if i%minors == 0 {
+			t.Label = formatTime(t.Value, subSecond, hours)
+		}
+		ret = append(ret, t)
+	}
+
+	return ret
+}
+
+// niceStep returns the smallest 1, 2, or 5 times a power of 10 that is at
+// least v, and a matching number of minor ticks per step.
+func niceStep(v float64) (step float64, minors int) {
+	base := math.Pow10(int(math.Floor(math.Log10(v))))
+	switch {
+	case v <= base:
+		return base, 5
+	case v <= 2*base:
+		return 2 * base, 4
+	case v <= 5*base:
+		return 5 * base, 5
+	default:
+		return 10 * base, 5
+	}
+}
+
+// formatTime formats a number of seconds as MM:SS, or H:MM:SS if hours is
+// true, or with an SI prefix if subSecond is true.
+func formatTime(v float64, subSecond, hours bool) string {
+	if subSecond {
+		return humanize.SI(v, "s")
+	}
+
+	sign := ""
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+
+	total := int64(math.Round(v))
+	h := total / 3600
+	m := total / 60 % 60
+	s := total % 60
+
+	if hours {
+		return fmt.Sprintf("%s%d:%02d:%02d", sign, h, m, s)
+	}
+	return fmt.Sprintf("%s%02d:%02d", sign, m, s)
+}
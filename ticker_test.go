@@ -0,0 +1,101 @@
+package plotext
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"gonum.org/v1/plot"
+)
+
+func TestLogTicker(t *testing.T) {
+	var ex []plot.Tick
+	for i, decade := range []float64{1, 10, 100} {
+		ex = append(ex, plot.Tick{Value: decade, Label: []string{"1", "10", "100"}[i]})
+		for k := 2.0; k <= 9; k++ {
+			ex = append(ex, plot.Tick{Value: k * decade})
+		}
+	}
+	ex = append(ex, plot.Tick{Value: 1000, Label: "1 k"})
+
+	ticks := LogTicker{}.Ticks(1, 1000)
+	if !slices.Equal(ticks, ex) {
+		t.Errorf("got: %v", ticks)
+		t.Errorf("expected: %v", ex)
+	}
+
+	// sub-decade ranges label every tick
+	ex = []plot.Tick{
+		{Value: 3, Label: "3"},
+		{Value: 4, Label: "4"},
+		{Value: 5, Label: "5"},
+		{Value: 6, Label: "6"},
+		{Value: 7, Label: "7"},
+	}
+	ticks = LogTicker{}.Ticks(3, 7)
+	if !slices.Equal(ticks, ex) {
+		t.Errorf("got: %v", ticks)
+		t.Errorf("expected: %v", ex)
+	}
+
+	// decades below 1 take SI prefixes
+	var labels []string
+	for _, tick := range (LogTicker{}).Ticks(0.001, 1) {
+		if tick.Label != "" {
+			labels = append(labels, tick.Label)
+		}
+	}
+	if ex := []string{"1 m", "10 m", "100 m", "1"}; !slices.Equal(labels, ex) {
+		t.Errorf("got labels %q, expected %q", labels, ex)
+	}
+}
+
+func TestTimeTicker(t *testing.T) {
+	table := []struct {
+		min, max float64
+		labels   []string
+	}{
+		{
+			0, 3700,
+			[]string{"0:00:00", "0:10:00", "0:20:00", "0:30:00", "0:40:00", "0:50:00", "1:00:00"},
+		},
+		{
+			0, 60,
+			[]string{"00:00", "00:10", "00:20", "00:30", "00:40", "00:50", "01:00"},
+		},
+		{
+			0, 0.5,
+			[]string{"0 s", "50 ms", "100 ms", "150 ms", "200 ms", "250 ms", "300 ms", "350 ms", "400 ms", "450 ms", "500 ms"},
+		},
+	}
+
+	for _, row := range table {
+		labels := []string{}
+		for _, tick := range (TimeTicker{}).Ticks(row.min, row.max) {
+			if tick.Label != "" {
+				labels = append(labels, tick.Label)
+			}
+		}
+		if !slices.Equal(labels, row.labels) {
+			t.Errorf("[%f, %f]: got labels %q, expected %q", row.min, row.max, labels, row.labels)
+		}
+	}
+
+	// tick values don't pick up rounding error, so the end is still a tick
+	ticks := TimeTicker{}.Ticks(0, 0.7)
+	for i, tick := range ticks {
+		if ex := math.Round(tick.Value*1e6) / 1e6; tick.Value != ex {
+			t.Errorf("[0, 0.7]: tick %d at %v, expected %v", i, tick.Value, ex)
+		}
+	}
+	if last := ticks[len(ticks)-1]; last.Value != 0.7 {
+		t.Errorf("[0, 0.7]: last tick at %v, expected 0.7", last.Value)
+	}
+
+	// a flat range is labeled by its magnitude, whatever its sign
+	for v, ex := range map[float64]string{5: "00:05", -5: "-00:05", 0.5: "500 ms", -0.5: "-500 ms", 7200: "2:00:00"} {
+		if got := (TimeTicker{}).Ticks(v, v); len(got) != 1 || got[0].Label != ex {
+			t.Errorf("flat range at %v: got %v, expected label %q", v, got, ex)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package plotext
+
+import (
+	"fmt"
+	"math"
+)
+
+// TimedBuffer is a time series of samples with their own timestamps, for data
+// without a fixed sample rate. It implements plotter.XYer with the timestamps
+// as X-values, and suits QuantizedLine, which buckets points by X.
+type TimedBuffer struct {
+	Times  []float64 // timestamp of each sample in seconds, nondecreasing
+	Values []float64
+}
+
+// NewTimedBuffer returns a TimedBuffer holding the given timestamps and values,
+// or an error if they aren't valid according to Validate.
+func NewTimedBuffer(times, values []float64) (*TimedBuffer, error) {
+	b := &TimedBuffer{Times: times, Values: values}
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Validate returns an error if Times and Values have different lengths, or if
+// the timestamps are NaN or not sorted in nondecreasing order.
+func (b *TimedBuffer) Validate() error {
+	if len(b.Times) != len(b.Values) {
+		return fmt.Errorf("plotext: %d timestamps for %d values", len(b.Times), len(b.Values))
+	}
+	for i, t := range b.Times {
+		if math.IsNaN(t) {
+			return fmt.Errorf("plotext: timestamp %d is NaN", i)
+		}
+		if i > 0 && t < b.Times[i-1] {
+			return fmt.Errorf("plotext: timestamp %d (%g) is before the previous one (%g)", i, t, b.Times[i-1])
+		}
+	}
+	return nil
+}
+
+// Len returns the number of x, y pairs.
+func (b *TimedBuffer) Len() int {
+	return len(b.Values)
+}
+
+// XY returns an x, y pair.
+func (b *TimedBuffer) XY(i int) (x float64, y float64) {
+	return b.Times[i], b.Values[i]
+}
+
+// DataRange returns the time span and the range of values of the buffer,
+// ignoring NaN values, implementing the plot.DataRanger interface. Like
+// SampleBuffer.DataRange, it returns an inverted infinite range for an empty
+// buffer.
+func (b *TimedBuffer) DataRange() (xmin, xmax, ymin, ymax float64) {
+	xmin, xmax = math.Inf(1), math.Inf(-1)
+	ymin, ymax = math.Inf(1), math.Inf(-1)
+
+	if len(b.Times) == 0 {
+		return
+	}
+
+	xmin, xmax = b.Times[0], b.Times[len(b.Times)-1]
+
+	for _, v := range b.Values {
+		if math.IsNaN(v) {
+			continue
+		}
+		ymin = min(ymin, v)
+		ymax = max(ymax, v)
+	}
+
+	return
+}
@@ -0,0 +1,40 @@
+package plotext
+
+import "testing"
+
+func TestTimedBuffer(t *testing.T) {
+	times := []float64{0, 0.1, 0.15, 0.9, 2}
+	values := []float64{1, -1, 3, 0, 2}
+
+	b, err := NewTimedBuffer(times, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Len() != len(times) {
+		t.Fatalf("got %d points, expected %d", b.Len(), len(times))
+	}
+	for i := range times {
+		if x, y := b.XY(i); x != times[i] || y != values[i] {
+			t.Errorf("point %d: got (%v, %v), expected (%v, %v)", i, x, y, times[i], values[i])
+		}
+	}
+	if xmin, xmax, ymin, ymax := b.DataRange(); xmin != 0 || xmax != 2 || ymin != -1 || ymax != 3 {
+		t.Errorf("got range [%v, %v] x [%v, %v]", xmin, xmax, ymin, ymax)
+	}
+
+	if _, err := NewTimedBuffer([]float64{0, 2, 1}, []float64{0, 0, 0}); err == nil {
+		t.Error("expected an error for unsorted timestamps")
+	}
+	if _, err := NewTimedBuffer([]float64{0, 1}, []float64{0}); err == nil {
+		t.Error("expected an error for mismatched lengths")
+	}
+
+	// irregular samples aggregate by time
+	ql, err := NewQuantizedLine(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ql.monotonicX() {
+		t.Error("sorted timestamps aren't monotonic")
+	}
+}
@@ -0,0 +1,324 @@
+package plotext
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Decimate returns a new SampleBuffer containing every `factor`-th sample of s,
+// with the SampleRate divided accordingly. The X values of the retained
+// samples are unchanged. Decimate panics if factor <= 0.
+func (s *SampleBuffer) Decimate(factor int) *SampleBuffer {
+	if factor <= 0 {
+		panic(fmt.Sprintf("plotext: invalid decimation factor %d", factor))
+	}
+
+	p := make([]float64, 0, (len(s.Samples)+factor-1)/factor)
+	for i := 0; i < len(s.Samples); i += factor {
+		p = append(p, s.Samples[i])
+	}
+
+	return &SampleBuffer{
+		Samples:    p,
+		SampleRate: s.SampleRate / float64(factor),
+		TimeOffset: s.startTime(),
+	}
+}
+
+// withSamples returns a new SampleBuffer holding p with the same sample rate
+// and time coordinates as s.
+func (s *SampleBuffer) withSamples(p []float64) *SampleBuffer {
+	return &SampleBuffer{
+		Samples:    p,
+		SampleRate: s.SampleRate,
+		StartIndex: s.StartIndex,
+		TimeOffset: s.TimeOffset,
+		IndexMode:  s.IndexMode,
+	}
+}
+
+// MovingAverage returns a new SampleBuffer where each sample is the mean of
+// the samples in a centered window of the given odd size. Near the ends of the
+// buffer the window shrinks to the samples available rather than padding.
+// NaN and infinite samples are gaps left out of the windows they fall in, and
+// a window with nothing but gaps averages to NaN.
+func (s *SampleBuffer) MovingAverage(window int) (*SampleBuffer, error) {
+	if window <= 0 || window%2 == 0 {
+		return nil, fmt.Errorf("plotext: moving average window must be odd and positive, got %d", window)
+	}
+
+	sums, counts := finitePrefixSums(s.Samples, func(v float64) float64 { return v })
+
+	half := window / 2
+	p := make([]float64, len(s.Samples))
+	for i := range p {
+		lo := max(0, i-half)
+		hi := min(len(s.Samples), i+half+1)
+		p[i] = (sums[hi] - sums[lo]) / float64(counts[hi]-counts[lo])
+	}
+
+	return s.withSamples(p), nil
+}
+
+// finitePrefixSums returns the running sums of f over the finite samples of p,
+// and the running counts of those samples, both starting at 0, so that the sum
+// and count of any window are O(1) differences. Non-finite samples are left
+// out, so that they don't carry over into every later window.
+func finitePrefixSums(p []float64, f func(float64) float64) (sums []float64, counts []int) {
+	sums = make([]float64, len(p)+1)
+	counts = make([]int, len(p)+1)
+	for i, v := range p {
+		sums[i+1], counts[i+1] = sums[i], counts[i]
+		if isFinite(v) {
+			sums[i+1] += f(v)
+			counts[i+1]++
+		}
+	}
+	return sums, counts
+}
+
+// ToDecibels returns a new SampleBuffer with each sample converted to decibels
+// relative to reference, 20*log10(|sample|/reference). Results below floor
+// (such as -Inf for zero samples) are clamped to floor, e.g. -120, to keep
+// plots bounded.
+func (s *SampleBuffer) ToDecibels(reference, floor float64) *SampleBuffer {
+	p := make([]float64, len(s.Samples))
+	for i, v := range s.Samples {
+		p[i] = max(floor, 20*math.Log10(math.Abs(v)/reference))
+	}
+	return s.withSamples(p)
+}
+
+// ResampleTo returns a new SampleBuffer with the given sample rate covering the
+// same time span as s, from its first sample to its last. New samples are
+// linearly interpolated between the nearest original samples; no band-limiting
+// is done, so downsampling may alias. ResampleTo panics if newRate <= 0.
+func (s *SampleBuffer) ResampleTo(newRate float64) *SampleBuffer {
+	if !(newRate > 0) {
+		panic(fmt.Sprintf("plotext: invalid resampling rate %g", newRate))
+	}
+
+	ret := &SampleBuffer{
+		SampleRate: newRate,
+		TimeOffset: s.startTime(),
+	}
+
+	if len(s.Samples) == 0 {
+		return ret
+	}
+
+	duration := float64(len(s.Samples)-1) / s.SampleRate
+	n := int(math.Floor(duration*newRate+1e-9)) + 1
+
+	ret.Samples = make([]float64, n)
+	last := len(s.Samples) - 1
+	for j := range ret.Samples {
+		pos := float64(j) * s.SampleRate / newRate
+		i := min(int(pos), last)
+		if i == last {
+			ret.Samples[j] = s.Samples[last]
+			continue
+		}
+		frac := pos - float64(i)
+		ret.Samples[j] = s.Samples[i]*(1-frac) + s.Samples[i+1]*frac
+	}
+
+	return ret
+}
+
+// Integrate returns a new SampleBuffer holding the running trapezoidal integral
+// of s with respect to time, using 1/SampleRate as the time step. The first
+// sample of the result is 0.
+func (s *SampleBuffer) Integrate() *SampleBuffer {
+	p := make([]float64, len(s.Samples))
+	dt := 1 / s.SampleRate
+	for i := 1; i < len(p); i++ {
+		p[i] = p[i-1] + (s.Samples[i-1]+s.Samples[i])/2*dt
+	}
+	return s.withSamples(p)
+}
+
+// Differentiate returns a new SampleBuffer holding the forward difference
+// (Samples[i+1]-Samples[i])*SampleRate of s. The last sample has no successor,
+// so it repeats the second-to-last difference to keep the same length.
+// Differentiate panics if SampleRate is zero.
+func (s *SampleBuffer) Differentiate() *SampleBuffer {
+	if s.SampleRate == 0 {
+		panic("plotext: can't differentiate a buffer with zero sample rate")
+	}
+
+	p := make([]float64, len(s.Samples))
+	for i := 0; i+1 < len(p); i++ {
+		p[i] = (s.Samples[i+1] - s.Samples[i]) * s.SampleRate
+	}
+	if n := len(p); n > 1 {
+		p[n-1] = p[n-2]
+	}
+	return s.withSamples(p)
+}
+
+// LowPass returns a new SampleBuffer holding s passed through a first-order RC
+// low-pass filter with the given -3 dB cutoff frequency in Hz. The filter
+// starts settled at the first sample, so there is no step at the beginning of
+// the output. The cutoff must be positive and below the Nyquist frequency.
+func (s *SampleBuffer) LowPass(cutoffHz float64) (*SampleBuffer, error) {
+	if nyquist := s.SampleRate / 2; !(cutoffHz > 0 && cutoffHz < nyquist) {
+		return nil, fmt.Errorf("plotext: low-pass cutoff %g Hz must be between 0 and the Nyquist frequency %g Hz", cutoffHz, nyquist)
+	}
+
+	dt := 1 / s.SampleRate
+	rc := 1 / (2 * math.Pi * cutoffHz)
+	alpha := dt / (rc + dt)
+
+	p := make([]float64, len(s.Samples))
+	for i, v := range s.Samples {
+		if i == 0 {
+			p[i] = v
+			continue
+		}
+		p[i] = p[i-1] + alpha*(v-p[i-1])
+	}
+	return s.withSamples(p), nil
+}
+
+// ConcatSampleBuffers returns a new SampleBuffer holding the samples of bufs in
+// order. The result keeps the time coordinates of the first buffer, so the X
+// axis continues across each join regardless of where the later segments
+// started. All buffers must have the same SampleRate.
+func ConcatSampleBuffers(bufs ...*SampleBuffer) (*SampleBuffer, error) {
+	if len(bufs) == 0 {
+		return nil, fmt.Errorf("plotext: no sample buffers to concatenate")
+	}
+
+	n := 0
+	for i, b := range bufs {
+		if b.SampleRate != bufs[0].SampleRate {
+			return nil, fmt.Errorf("plotext: buffer %d has sample rate %g, expected %g", i, b.SampleRate, bufs[0].SampleRate)
+		}
+		n += len(b.Samples)
+	}
+
+	p := make([]float64, 0, n)
+	for _, b := range bufs {
+		p = append(p, b.Samples...)
+	}
+	return bufs[0].withSamples(p), nil
+}
+
+// NormMode selects how Normalize scales the samples of a SampleBuffer.
+type NormMode int
+
+const (
+	// PeakNorm scales the samples so that the largest magnitude is 1, keeping
+	// them within [-1, 1] and keeping zero at zero.
+	PeakNorm NormMode = iota
+
+	// MinMaxNorm shifts and scales the samples onto [0, 1], with the smallest
+	// sample at 0 and the largest at 1.
+	MinMaxNorm
+)
+
+// Normalize returns a new SampleBuffer with the samples of s scaled according
+// to mode. A buffer with no range to scale (all zeros for PeakNorm, or
+// constant for MinMaxNorm) normalizes to all zeros. The range is taken over
+// the finite samples only, so gaps stay gaps and don't wipe out the rest.
+func (s *SampleBuffer) Normalize(mode NormMode) *SampleBuffer {
+	lo, hi := s.Min(), s.Max()
+
+	var offset, scale float64
+	switch mode {
+	case MinMaxNorm:
+		offset = lo
+		if hi > lo {
+			scale = 1 / (hi - lo)
+		}
+	default:
+		if peak := max(math.Abs(lo), math.Abs(hi)); peak > 0 {
+			scale = 1 / peak
+		}
+	}
+
+	p := make([]float64, len(s.Samples))
+	for i, v := range s.Samples {
+		p[i] = (v - offset) * scale
+	}
+	return s.withSamples(p)
+}
+
+// RollingRMS returns a new SampleBuffer where each sample is the root mean
+// square of s over a centered window of windowSeconds, rounded to a whole
+// number of samples but at least one. Like MovingAverage, the window shrinks
+// to the samples available near the ends of the buffer, and leaves out NaN
+// and infinite samples as gaps.
+func (s *SampleBuffer) RollingRMS(windowSeconds float64) *SampleBuffer {
+	window := max(1, int(math.Round(windowSeconds*s.SampleRate)))
+
+	sums, counts := finitePrefixSums(s.Samples, func(v float64) float64 { return v * v })
+
+	p := make([]float64, len(s.Samples))
+	for i := range p {
+		lo := max(0, i-window/2)
+		hi := min(len(s.Samples), i+window-window/2)
+		// rounding can leave a tiny negative difference for silent windows
+		p[i] = math.Sqrt(max(0, sums[hi]-sums[lo]) / float64(counts[hi]-counts[lo]))
+	}
+	return s.withSamples(p)
+}
+
+// TrimFlat returns a new SampleBuffer holding s without the leading and
+// trailing runs of samples whose magnitude is below threshold. StartIndex is
+// advanced past the removed leading samples, so the retained samples keep
+// their original X values. If every sample is below threshold, the result is
+// empty.
+func (s *SampleBuffer) TrimFlat(threshold float64) *SampleBuffer {
+	start, end := 0, len(s.Samples)
+	for start < end && math.Abs(s.Samples[start]) < threshold {
+		start++
+	}
+	for end > start && math.Abs(s.Samples[end-1]) < threshold {
+		end--
+	}
+
+	ret := s.withSamples(slices.Clone(s.Samples[start:end]))
+	ret.StartIndex += start
+	return ret
+}
+
+// SliceTime returns a SampleBuffer holding the samples of s with X values in
+// [startSec, endSec), clamped to the buffer. Like a slice expression, it
+// shares the samples of s. StartIndex is advanced past the samples before the
+// range, so the retained samples keep their original X values.
+func (s *SampleBuffer) SliceTime(startSec, endSec float64) *SampleBuffer {
+	// index of the first sample at or after t, tolerating rounding error in
+	// the conversion
+	index := func(t float64) int {
+		i := math.Ceil((t-s.startTime())*s.SampleRate - 1e-9)
+		return int(max(0, min(i, float64(len(s.Samples)))))
+	}
+
+	start, end := index(startSec), index(endSec)
+	end = max(start, end)
+
+	ret := s.withSamples(s.Samples[start:end])
+	ret.StartIndex += start
+	return ret
+}
+
+// PadTo returns a new SampleBuffer holding the samples of s followed by as
+// many copies of value as it takes to make length samples. If s already has
+// at least length samples, the result is an unpadded copy.
+func (s *SampleBuffer) PadTo(length int, value float64) *SampleBuffer {
+	p := make([]float64, max(length, len(s.Samples)))
+	n := copy(p, s.Samples)
+	for i := n; i < len(p); i++ {
+		p[i] = value
+	}
+	return s.withSamples(p)
+}
+
+// PadToPow2 returns a new SampleBuffer holding the samples of s zero-padded to
+// the next power of two in length, as for an FFT.
+func (s *SampleBuffer) PadToPow2() *SampleBuffer {
+	return s.PadTo(nextPow2(len(s.Samples)), 0)
+}
@@ -0,0 +1,481 @@
+package plotext
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestDecimate(t *testing.T) {
+	s := &SampleBuffer{
+		Samples:    []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		SampleRate: 100,
+		StartIndex: 10,
+	}
+
+	for _, factor := range []int{1, 2, 3, 4, 10, 11} {
+		d := s.Decimate(factor)
+		if ex := (len(s.Samples) + factor - 1) / factor; d.Len() != ex {
+			t.Errorf("factor %d: got length %d, expected %d", factor, d.Len(), ex)
+		}
+		if ex := s.SampleRate / float64(factor); d.SampleRate != ex {
+			t.Errorf("factor %d: got sample rate %f, expected %f", factor, d.SampleRate, ex)
+		}
+		for i := 0; i < d.Len(); i++ {
+			x, y := d.XY(i)
+			exX, exY := s.XY(i * factor)
+			if math.Abs(x-exX) > 1e-12 || y != exY {
+				t.Errorf("factor %d: sample %d: got (%f, %f), expected (%f, %f)", factor, i, x, y, exX, exY)
+			}
+		}
+	}
+}
+
+func TestMovingAverage(t *testing.T) {
+	s := &SampleBuffer{
+		Samples:    []float64{0, 0, 0, 0, 3, 3, 3, 3},
+		SampleRate: 10,
+	}
+
+	avg, err := s.MovingAverage(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ex := []float64{0, 0, 0, 1, 2, 3, 3, 3}
+	for i, v := range avg.Samples {
+		if math.Abs(v-ex[i]) > 1e-12 {
+			t.Errorf("got %v, expected %v", avg.Samples, ex)
+			break
+		}
+	}
+	if avg.SampleRate != s.SampleRate {
+		t.Errorf("got sample rate %f, expected %f", avg.SampleRate, s.SampleRate)
+	}
+
+	// the window shrinks at the edges
+	avg, err = s.MovingAverage(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := avg.Samples[len(avg.Samples)-1]; v != 3 {
+		t.Errorf("got last sample %f, expected 3", v)
+	}
+	if v := avg.Samples[3]; math.Abs(v-1.2) > 1e-12 {
+		t.Errorf("got transition sample %f, expected 1.2", v)
+	}
+
+	for _, window := range []int{0, -1, 2, 4} {
+		if _, err := s.MovingAverage(window); err == nil {
+			t.Errorf("window %d: expected error", window)
+		}
+	}
+}
+
+// finiteWindowMean returns the mean of f over the finite samples of p in
+// [lo, hi), or NaN if there are none.
+func finiteWindowMean(p []float64, lo, hi int, f func(float64) float64) float64 {
+	sum, n := 0.0, 0
+	for _, v := range p[max(0, lo):min(len(p), hi)] {
+		if isFinite(v) {
+			sum += f(v)
+			n++
+		}
+	}
+	return sum / float64(n)
+}
+
+// gappySamples returns samples with a one-sample NaN gap at 10 and a
+// three-sample gap at 500.
+func gappySamples() *SampleBuffer {
+	s := &SampleBuffer{Samples: make([]float64, 1000), SampleRate: 10}
+	for i := range s.Samples {
+		s.Samples[i] = float64(i%3) - 0.5
+	}
+	s.Samples[10] = math.NaN()
+	s.Samples[500], s.Samples[501], s.Samples[502] = math.NaN(), math.NaN(), math.NaN()
+	return s
+}
+
+func TestMovingAverageNaNGap(t *testing.T) {
+	s := gappySamples()
+	avg, err := s.MovingAverage(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// gaps are left out of the windows they fall in and don't carry on
+	// past them, and a window of nothing but gaps is NaN
+	for i, v := range avg.Samples {
+		ex := finiteWindowMean(s.Samples, i-1, i+2, func(v float64) float64 { return v })
+		if math.IsNaN(ex) != math.IsNaN(v) || math.Abs(v-ex) > 1e-12 {
+			t.Errorf("sample %d: got %v, expected %v", i, v, ex)
+		}
+	}
+	if !math.IsNaN(avg.Samples[501]) || math.IsNaN(avg.Samples[900]) {
+		t.Errorf("got %v at 501 and %v at 900", avg.Samples[501], avg.Samples[900])
+	}
+}
+
+func TestToDecibels(t *testing.T) {
+	s := &SampleBuffer{
+		Samples:    []float64{1, -10, 0.1, 2, 0, 1e-9},
+		SampleRate: 1,
+	}
+	ex := []float64{0, 20, -20, 6.0206, -120, -120}
+
+	db := s.ToDecibels(1, -120)
+	for i, v := range db.Samples {
+		if math.Abs(v-ex[i]) > 1e-4 {
+			t.Errorf("sample %d: got %f dB, expected %f dB", i, v, ex[i])
+		}
+	}
+}
+
+func TestResampleTo(t *testing.T) {
+	s := &SampleBuffer{
+		Samples:    []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		SampleRate: 10,
+		TimeOffset: 1,
+	}
+
+	up := s.ResampleTo(20)
+	if up.Len() != 19 {
+		t.Fatalf("upsampled: got %d samples, expected 19", up.Len())
+	}
+	for i := 0; i < up.Len(); i++ {
+		x, y := up.XY(i)
+		if exX, exY := 1+float64(i)/20, float64(i)/2; math.Abs(x-exX) > 1e-12 || math.Abs(y-exY) > 1e-12 {
+			t.Errorf("upsampled sample %d: got (%f, %f), expected (%f, %f)", i, x, y, exX, exY)
+		}
+	}
+
+	down := s.ResampleTo(4)
+	ex := []float64{0, 2.5, 5, 7.5}
+	if down.Len() != len(ex) {
+		t.Fatalf("downsampled: got %d samples, expected %d", down.Len(), len(ex))
+	}
+	for i, v := range down.Samples {
+		if math.Abs(v-ex[i]) > 1e-12 {
+			t.Errorf("downsampled: got %v, expected %v", down.Samples, ex)
+			break
+		}
+	}
+
+	for _, rate := range []float64{0, -10, math.NaN()} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("rate %v: expected a panic", rate)
+				}
+			}()
+			s.ResampleTo(rate)
+		}()
+	}
+}
+
+func TestIntegrate(t *testing.T) {
+	const c = 3.0
+	s := &SampleBuffer{Samples: make([]float64, 100), SampleRate: 50}
+	for i := range s.Samples {
+		s.Samples[i] = c
+	}
+
+	in := s.Integrate()
+	if in.Len() != s.Len() || in.SampleRate != s.SampleRate {
+		t.Fatalf("got %d samples at %f Hz, expected %d at %f Hz", in.Len(), in.SampleRate, s.Len(), s.SampleRate)
+	}
+	if in.Samples[0] != 0 {
+		t.Errorf("got first sample %f, expected 0", in.Samples[0])
+	}
+	for i := 1; i < in.Len(); i++ {
+		x0, y0 := in.XY(i - 1)
+		x1, y1 := in.XY(i)
+		if slope := (y1 - y0) / (x1 - x0); math.Abs(slope-c) > 1e-9 {
+			t.Errorf("sample %d: got slope %f, expected %f", i, slope, c)
+			break
+		}
+	}
+}
+
+func TestDifferentiate(t *testing.T) {
+	const slope = -2.5
+	s := &SampleBuffer{Samples: make([]float64, 20), SampleRate: 8}
+	for i := range s.Samples {
+		x, _ := s.XY(i)
+		s.Samples[i] = 1 + slope*x
+	}
+
+	d := s.Differentiate()
+	if d.Len() != s.Len() {
+		t.Fatalf("got %d samples, expected %d", d.Len(), s.Len())
+	}
+	for i, v := range d.Samples {
+		if math.Abs(v-slope) > 1e-9 {
+			t.Errorf("sample %d: got %f, expected %f", i, v, slope)
+		}
+	}
+}
+
+func TestLowPass(t *testing.T) {
+	const fs = 1000.0
+
+	rng := rand.New(rand.NewSource(1))
+	s := &SampleBuffer{Samples: make([]float64, 4096), SampleRate: fs}
+	for i := range s.Samples {
+		s.Samples[i] = rng.Float64()*2 - 1
+	}
+
+	lp, err := s.LowPass(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lp.Len() != s.Len() {
+		t.Fatalf("got %d samples, expected %d", lp.Len(), s.Len())
+	}
+
+	// mean magnitude of the spectrum above 200 Hz
+	highBand := func(buf *SampleBuffer) float64 {
+		spec := buf.Spectrum()
+		var sum float64
+		var n int
+		for i := 0; i < spec.Len(); i++ {
+			if x, y := spec.XY(i); x > 200 {
+				sum += y
+				n++
+			}
+		}
+		return sum / float64(n)
+	}
+
+	before, after := highBand(s), highBand(lp)
+	if after > before/10 {
+		t.Errorf("high band magnitude went from %g to %g, expected at least 20 dB attenuation", before, after)
+	}
+
+	for _, cutoff := range []float64{0, -1, fs / 2, fs} {
+		if _, err := s.LowPass(cutoff); err == nil {
+			t.Errorf("expected an error for cutoff %g Hz", cutoff)
+		}
+	}
+}
+
+func TestConcatSampleBuffers(t *testing.T) {
+	a := &SampleBuffer{Samples: []float64{0, 1, 2}, SampleRate: 10, TimeOffset: 5}
+	b := &SampleBuffer{Samples: []float64{3, 4}, SampleRate: 10}
+	c := &SampleBuffer{Samples: []float64{5, 6, 7, 8}, SampleRate: 10, StartIndex: 100}
+
+	s, err := ConcatSampleBuffers(a, b, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Len() != 9 {
+		t.Fatalf("got %d samples, expected 9", s.Len())
+	}
+
+	prev := math.Inf(-1)
+	for i := 0; i < s.Len(); i++ {
+		x, y := s.XY(i)
+		if y != float64(i) {
+			t.Errorf("sample %d = %v, expected %v", i, y, float64(i))
+		}
+		if x <= prev {
+			t.Errorf("x[%d] = %v is not after x[%d] = %v", i, x, i-1, prev)
+		}
+		prev = x
+	}
+	if x, _ := s.XY(0); x != 5 {
+		t.Errorf("got first x %v, expected 5", x)
+	}
+
+	if _, err := ConcatSampleBuffers(a, &SampleBuffer{SampleRate: 20}); err == nil {
+		t.Error("expected an error for mismatched sample rates")
+	}
+	if _, err := ConcatSampleBuffers(); err == nil {
+		t.Error("expected an error for no buffers")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	s := &SampleBuffer{Samples: []float64{-2, 1, 4, 0}, SampleRate: 10}
+
+	peak := s.Normalize(PeakNorm)
+	if peak.Max() != 1 || peak.Min() != -0.5 || peak.Samples[3] != 0 {
+		t.Errorf("peak: got %v", peak.Samples)
+	}
+
+	neg := &SampleBuffer{Samples: []float64{-4, 2}, SampleRate: 10}
+	if got := neg.Normalize(PeakNorm); got.Min() != -1 || got.Max() != 0.5 {
+		t.Errorf("peak: got %v", got.Samples)
+	}
+
+	mm := s.Normalize(MinMaxNorm)
+	if mm.Max() != 1 || mm.Min() != 0 || mm.SampleRate != s.SampleRate {
+		t.Errorf("min-max: got %v at %v", mm.Samples, mm.SampleRate)
+	}
+
+	flat := &SampleBuffer{Samples: []float64{3, 3, 3}, SampleRate: 10}
+	for _, mode := range []NormMode{PeakNorm, MinMaxNorm} {
+		for i, v := range flat.Normalize(mode).Samples {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				t.Errorf("mode %d: sample %d = %v", mode, i, v)
+			}
+		}
+	}
+	zeros := &SampleBuffer{Samples: []float64{0, 0}, SampleRate: 10}
+	if got := zeros.Normalize(PeakNorm).Samples; got[0] != 0 || got[1] != 0 {
+		t.Errorf("zeros: got %v", got)
+	}
+}
+
+func TestNormalizeNaNGap(t *testing.T) {
+	s := &SampleBuffer{Samples: []float64{-2, 1, math.NaN(), 4}, SampleRate: 10}
+	for mode, ex := range map[NormMode][]float64{
+		PeakNorm:   {-0.5, 0.25, math.NaN(), 1},
+		MinMaxNorm: {0, 0.5, math.NaN(), 1},
+	} {
+		got := s.Normalize(mode).Samples
+		if !slices.EqualFunc(got, ex, func(a, b float64) bool {
+			return a == b || math.IsNaN(a) && math.IsNaN(b)
+		}) {
+			t.Errorf("mode %d: got %v, expected %v", mode, got, ex)
+		}
+	}
+}
+
+func TestRollingRMS(t *testing.T) {
+	const amplitude = 2.0
+	s := sineBuffer(2000, 1000, 50, amplitude)
+
+	// a whole number of periods per window
+	r := s.RollingRMS(0.1)
+	if r.Len() != s.Len() || r.SampleRate != s.SampleRate {
+		t.Fatalf("got %d samples at %v, expected %d at %v", r.Len(), r.SampleRate, s.Len(), s.SampleRate)
+	}
+	ex := amplitude / math.Sqrt2
+	for i := 100; i < r.Len()-100; i++ {
+		if math.Abs(r.Samples[i]-ex) > 1e-6 {
+			t.Fatalf("sample %d: got %f, expected %f", i, r.Samples[i], ex)
+		}
+	}
+
+	// a window shorter than a sample passes magnitudes through
+	for i, v := range s.RollingRMS(0).Samples {
+		if math.Abs(v-math.Abs(s.Samples[i])) > 1e-9 {
+			t.Fatalf("sample %d: got %f, expected %f", i, v, math.Abs(s.Samples[i]))
+		}
+	}
+
+	// at the ends the window shrinks like MovingAverage's, rather than
+	// shifting inward and picking up the step early
+	step := &SampleBuffer{Samples: []float64{0, 0, 0, 0, 1, 1, 1, 1, 1, 1, 0}, SampleRate: 10}
+	squares := step.withSamples(make([]float64, step.Len()))
+	for i, v := range step.Samples {
+		squares.Samples[i] = v * v
+	}
+	avg, err := squares.MovingAverage(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range step.RollingRMS(0.5).Samples {
+		if ex := math.Sqrt(avg.Samples[i]); math.Abs(v-ex) > 1e-9 {
+			t.Errorf("step sample %d: got %f, expected %f", i, v, ex)
+		}
+	}
+}
+
+func TestRollingRMSNaNGap(t *testing.T) {
+	s := gappySamples()
+	r := s.RollingRMS(0.5) // 5 samples
+
+	for i, v := range r.Samples {
+		ex := math.Sqrt(finiteWindowMean(s.Samples, i-2, i+3, func(v float64) float64 { return v * v }))
+		if math.IsNaN(ex) != math.IsNaN(v) || math.Abs(v-ex) > 1e-12 {
+			t.Errorf("sample %d: got %v, expected %v", i, v, ex)
+		}
+	}
+	if math.IsNaN(r.Samples[900]) {
+		t.Error("the gap at sample 10 carried through to sample 900")
+	}
+}
+
+func TestTrimFlat(t *testing.T) {
+	s := &SampleBuffer{
+		Samples:    []float64{0, 0.01, -0.02, 0, 1, -0.5, 0, 2, 0.03, 0, 0},
+		SampleRate: 10,
+		TimeOffset: 3,
+	}
+
+	trimmed := s.TrimFlat(0.1)
+	ex := []float64{1, -0.5, 0, 2}
+	if !slices.Equal(trimmed.Samples, ex) {
+		t.Fatalf("got %v, expected %v", trimmed.Samples, ex)
+	}
+	for i := range ex {
+		x, _ := trimmed.XY(i)
+		if sx, _ := s.XY(i + 4); x != sx {
+			t.Errorf("sample %d: got x %v, expected the original %v", i, x, sx)
+		}
+	}
+
+	if n := s.TrimFlat(5).Len(); n != 0 {
+		t.Errorf("got %d samples above a threshold over every sample, expected none", n)
+	}
+	if n := s.TrimFlat(0).Len(); n != s.Len() {
+		t.Errorf("got %d samples with no threshold, expected all %d", n, s.Len())
+	}
+}
+
+func TestSliceTime(t *testing.T) {
+	s := sineBuffer(1000, 1000, 5, 1)
+
+	slice := s.SliceTime(0.25, 0.75)
+	if slice.Len() != 500 {
+		t.Errorf("got %d samples, expected 500", slice.Len())
+	}
+	if x, y := slice.XY(0); x != 0.25 || y != s.Samples[250] {
+		t.Errorf("got first point (%v, %v), expected (0.25, %v)", x, y, s.Samples[250])
+	}
+	if x, _ := slice.XY(slice.Len() - 1); math.Abs(x-0.749) > 1e-12 {
+		t.Errorf("got last x %v, expected 0.749", x)
+	}
+
+	// an offset buffer is sliced by its own time coordinates
+	s.TimeOffset = 10
+	if slice := s.SliceTime(10.5, 11); slice.Len() != 500 {
+		t.Errorf("offset: got %d samples, expected 500", slice.Len())
+	} else if x, _ := slice.XY(0); x != 10.5 {
+		t.Errorf("offset: got first x %v, expected 10.5", x)
+	}
+
+	if n := s.SliceTime(-100, 100).Len(); n != s.Len() {
+		t.Errorf("got %d samples for a range covering the buffer, expected %d", n, s.Len())
+	}
+	if n := s.SliceTime(11, 10).Len(); n != 0 {
+		t.Errorf("got %d samples for an inverted range, expected none", n)
+	}
+}
+
+func TestPadTo(t *testing.T) {
+	s := &SampleBuffer{Samples: []float64{1, 2, 3, 4, 5}, SampleRate: 10, TimeOffset: 1}
+
+	padded := s.PadTo(8, -1)
+	if ex := []float64{1, 2, 3, 4, 5, -1, -1, -1}; !slices.Equal(padded.Samples, ex) {
+		t.Errorf("got %v, expected %v", padded.Samples, ex)
+	}
+	if padded.SampleRate != s.SampleRate || padded.TimeOffset != s.TimeOffset {
+		t.Errorf("got rate %v and offset %v, expected %v and %v", padded.SampleRate, padded.TimeOffset, s.SampleRate, s.TimeOffset)
+	}
+
+	if short := s.PadTo(3, 0); !slices.Equal(short.Samples, s.Samples) {
+		t.Errorf("got %v padding to a shorter length, expected the samples unchanged", short.Samples)
+	}
+
+	if ex := []float64{1, 2, 3, 4, 5, 0, 0, 0}; !slices.Equal(s.PadToPow2().Samples, ex) {
+		t.Errorf("got %v, expected %v", s.PadToPow2().Samples, ex)
+	}
+	pow2 := &SampleBuffer{Samples: []float64{1, 2, 3, 4}}
+	if n := pow2.PadToPow2().Len(); n != 4 {
+		t.Errorf("got %d samples padding 4 to a power of two, expected 4", n)
+	}
+}
@@ -0,0 +1,117 @@
+package plotext
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// LoadSampleBufferWAV loads the first channel of the PCM WAV file at `path`,
+// with the sample rate from its header. 16- and 24-bit integer samples are
+// supported, including in WAVE_FORMAT_EXTENSIBLE files with a PCM subformat,
+// and are scaled to [-1, 1).
+func LoadSampleBufferWAV(path string) (*SampleBuffer, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := parseWAV(b)
+	if err != nil {
+		return nil, fmt.Errorf("plotext: %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// WAV format tags.
+const (
+	formatPCM        = 1
+	formatExtensible = 0xfffe
+)
+
+// pcmSubformat is the KSDATAFORMAT_SUBTYPE_PCM GUID identifying PCM samples in
+// a WAVE_FORMAT_EXTENSIBLE fmt chunk.
+var pcmSubformat = [16]byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x80, 0x00, 0x00, 0xaa, 0x00, 0x38, 0x9b, 0x71}
+
+// parseWAV decodes the first channel of a PCM WAV file held in b.
+func parseWAV(b []byte) (*SampleBuffer, error) {
+	if len(b) < 12 || string(b[0:4]) != "RIFF" || string(b[8:12]) != "WAVE" {
+		return nil, errors.New("not a WAV file")
+	}
+
+	var (
+		format, channels, bits uint16
+		rate                   uint32
+		haveFormat             bool
+		data                   []byte
+	)
+
+	// walk the chunks after the RIFF header
+	for rest := b[12:]; len(rest) >= 8; {
+		id := string(rest[0:4])
+		size := int(binary.LittleEndian.Uint32(rest[4:8]))
+		rest = rest[8:]
+		if size > len(rest) {
+			return nil, fmt.Errorf("%q chunk is truncated", id)
+		}
+		chunk := rest[:size]
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, errors.New("fmt chunk is too short")
+			}
+			format = binary.LittleEndian.Uint16(chunk[0:2])
+			channels = binary.LittleEndian.Uint16(chunk[2:4])
+			rate = binary.LittleEndian.Uint32(chunk[4:8])
+			bits = binary.LittleEndian.Uint16(chunk[14:16])
+			haveFormat = true
+
+			// WAVE_FORMAT_EXTENSIBLE, as written by ffmpeg and others for
+			// more than 16 bits, moves the real format into a subformat GUID
+			if format == formatExtensible && size >= 40 && bytes.Equal(chunk[24:40], pcmSubformat[:]) {
+				format = formatPCM
+			}
+		case "data":
+			data = chunk
+		}
+
+		// chunks are padded to an even size
+		rest = rest[min(len(rest), size+size%2):]
+	}
+
+	switch {
+	case !haveFormat:
+		return nil, errors.New("missing fmt chunk")
+	case data == nil:
+		return nil, errors.New("missing data chunk")
+	case format != formatPCM:
+		return nil, fmt.Errorf("unsupported audio format %d, expected PCM", format)
+	case bits != 16 && bits != 24:
+		return nil, fmt.Errorf("unsupported sample width %d bits", bits)
+	case channels == 0:
+		return nil, errors.New("no channels")
+	}
+
+	width := int(bits) / 8
+	frame := width * int(channels)
+	p := make([]float64, len(data)/frame)
+	for i := range p {
+		v := data[i*frame:]
+		switch bits {
+		case 16:
+			p[i] = float64(int16(binary.LittleEndian.Uint16(v))) / (1 << 15)
+		case 24:
+			// sign-extend from the top byte
+			n := int32(uint32(v[0])<<8|uint32(v[1])<<16|uint32(v[2])<<24) >> 8
+			p[i] = float64(n) / (1 << 23)
+		}
+	}
+
+	return &SampleBuffer{
+		Samples:    p,
+		SampleRate: float64(rate),
+	}, nil
+}
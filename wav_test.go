@@ -0,0 +1,129 @@
+package plotext
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// makeWAV returns a PCM WAV file holding the given raw little-endian sample
+// frames.
+func makeWAV(rate uint32, channels, bits uint16, frames []byte) []byte {
+	return makeWAVFormat(1, nil, rate, channels, bits, frames)
+}
+
+// makeWAVExtensible is like makeWAV, but writes a WAVE_FORMAT_EXTENSIBLE fmt
+// chunk with the given subformat GUID.
+func makeWAVExtensible(subformat [16]byte, rate uint32, channels, bits uint16, frames []byte) []byte {
+	var ext bytes.Buffer
+	binary.Write(&ext, binary.LittleEndian, uint16(22)) // extension size
+	binary.Write(&ext, binary.LittleEndian, bits)       // valid bits
+	binary.Write(&ext, binary.LittleEndian, uint32(0))  // channel mask
+	ext.Write(subformat[:])
+	return makeWAVFormat(0xfffe, ext.Bytes(), rate, channels, bits, frames)
+}
+
+// makeWAVFormat returns a WAV file with the given format tag and fmt chunk
+// extension holding the given raw sample frames.
+func makeWAVFormat(format uint16, ext []byte, rate uint32, channels, bits uint16, frames []byte) []byte {
+	var b bytes.Buffer
+	le := func(v any) { binary.Write(&b, binary.LittleEndian, v) }
+
+	b.WriteString("RIFF")
+	le(uint32(4 + 8 + 16 + len(ext) + 8 + len(frames)))
+	b.WriteString("WAVE")
+
+	b.WriteString("fmt ")
+	le(uint32(16 + len(ext)))
+	le(format)
+	le(channels)
+	le(rate)
+	le(rate * uint32(channels) * uint32(bits/8))
+	le(channels * bits / 8)
+	le(bits)
+	b.Write(ext)
+
+	b.WriteString("data")
+	le(uint32(len(frames)))
+	b.Write(frames)
+	return b.Bytes()
+}
+
+func TestLoadSampleBufferWAV(t *testing.T) {
+	dir := t.TempDir()
+
+	// 16-bit stereo: the second channel must be ignored
+	var frames bytes.Buffer
+	for _, v := range []int16{0, 1000, 16384, 1000, -32768, 1000, 32767, 1000} {
+		binary.Write(&frames, binary.LittleEndian, v)
+	}
+	path := filepath.Join(dir, "16.wav")
+	if err := os.WriteFile(path, makeWAV(8000, 2, 16, frames.Bytes()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadSampleBufferWAV(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.SampleRate != 8000 {
+		t.Errorf("got sample rate %v, expected 8000", s.SampleRate)
+	}
+	ex := []float64{0, 0.5, -1, 32767.0 / 32768}
+	if len(s.Samples) != len(ex) {
+		t.Fatalf("got samples %v, expected %v", s.Samples, ex)
+	}
+	for i := range ex {
+		if s.Samples[i] != ex[i] {
+			t.Errorf("sample %d: got %v, expected %v", i, s.Samples[i], ex[i])
+		}
+	}
+
+	// 24-bit mono: 0.5, -0.25
+	path = filepath.Join(dir, "24.wav")
+	if err := os.WriteFile(path, makeWAV(48000, 1, 24, []byte{0, 0, 0x40, 0, 0, 0xe0}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err = LoadSampleBufferWAV(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Samples) != 2 || s.Samples[0] != 0.5 || s.Samples[1] != -0.25 || s.SampleRate != 48000 {
+		t.Errorf("24-bit: got %v at %v", s.Samples, s.SampleRate)
+	}
+
+	// 24-bit in an extensible fmt chunk, as ffmpeg writes it
+	path = filepath.Join(dir, "24ext.wav")
+	if err := os.WriteFile(path, makeWAVExtensible(pcmSubformat, 48000, 1, 24, []byte{0, 0, 0x40, 0, 0, 0xe0}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err = LoadSampleBufferWAV(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Samples) != 2 || s.Samples[0] != 0.5 || s.Samples[1] != -0.25 || s.SampleRate != 48000 {
+		t.Errorf("24-bit extensible: got %v at %v", s.Samples, s.SampleRate)
+	}
+
+	// but not with a floating point subformat
+	float := pcmSubformat
+	float[0] = 3
+	path = filepath.Join(dir, "float.wav")
+	if err := os.WriteFile(path, makeWAVExtensible(float, 48000, 1, 24, []byte{0, 0, 0x40}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSampleBufferWAV(path); err == nil {
+		t.Error("expected an error for a non-PCM subformat")
+	}
+
+	// 8-bit is unsupported
+	path = filepath.Join(dir, "8.wav")
+	if err := os.WriteFile(path, makeWAV(8000, 1, 8, []byte{128}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSampleBufferWAV(path); err == nil {
+		t.Error("expected an error for 8-bit samples")
+	}
+}